@@ -22,6 +22,7 @@ package v1beta1
 
 import (
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -43,10 +44,62 @@ type RegistryCache struct {
 var (
 	ConditionTypeStartup = "Starting"
 	ConditionReasonReady = "Ready"
+
+	ConditionTypePolicyValid     = "PolicyValid"
+	ConditionReasonPolicyValid   = "PolicyValid"
+	ConditionReasonPolicyInvalid = "PolicyInvalid"
 )
 
 // RegistryCacheSpec defines the desired state of RegistryCache
-type RegistryCacheSpec struct{}
+type RegistryCacheSpec struct {
+	// Policy restricts the RegistryCacheConfig resources which can be created in the cluster.
+	// +optional
+	Policy *RegistryCachePolicy `json:"policy,omitempty"`
+}
+
+// RegistryCachePolicy is enforced by the RegistryCacheConfig admission webhook.
+// An invalid policy is not enforced, the RegistryCache is in the Warning state instead.
+type RegistryCachePolicy struct {
+	// AllowedUpstreams are patterns of the upstreams which can be cached, for example, `docker.io` or `*.example.com`.
+	// The patterns use the syntax of path.Match and are matched against the normalized upstream.
+	// All upstreams are allowed if empty.
+	// +optional
+	AllowedUpstreams []string `json:"allowedUpstreams,omitempty"`
+
+	// DeniedUpstreams are patterns of the upstreams which cannot be cached. They take precedence over AllowedUpstreams.
+	// +optional
+	DeniedUpstreams []string `json:"deniedUpstreams,omitempty"`
+
+	// NamespaceSelector selects the namespaces in which RegistryCacheConfig resources can be created.
+	// All namespaces are permitted if not set.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// MaxConfigs is the maximum number of RegistryCacheConfig resources in the cluster.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxConfigs *int32 `json:"maxConfigs,omitempty"`
+
+	// Storage limits the cache volumes of the RegistryCacheConfig resources.
+	// +optional
+	Storage *StoragePolicy `json:"storage,omitempty"`
+}
+
+// StoragePolicy limits the volume sizes of the RegistryCacheConfig resources.
+// The default volume size of 10Gi applies to the RegistryCacheConfig resources which do not set spec.volume.size.
+type StoragePolicy struct {
+	// MinVolumeSize is the minimum volume size of a RegistryCacheConfig.
+	// +optional
+	MinVolumeSize *resource.Quantity `json:"minVolumeSize,omitempty"`
+
+	// MaxVolumeSize is the maximum volume size of a RegistryCacheConfig.
+	// +optional
+	MaxVolumeSize *resource.Quantity `json:"maxVolumeSize,omitempty"`
+
+	// Budget is the maximum sum of the volume sizes of all RegistryCacheConfig resources in the cluster.
+	// +optional
+	Budget *resource.Quantity `json:"budget,omitempty"`
+}
 
 // Valid RegistryCache States.
 const (
@@ -84,6 +137,44 @@ type RegistryCacheStatus struct {
 	// Conditions contain a set of conditionals to determine the State of Status.
 	// If all Conditions are met, State is expected to be in StateReady.
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Recommendations are the upstreams of the images of the Pods in the cluster which are worth caching.
+	// +optional
+	Recommendations *UpstreamRecommendations `json:"recommendations,omitempty"`
+}
+
+// UpstreamRecommendations lists the upstreams without a RegistryCacheConfig, ranked by the estimated number of image pulls
+// and the number of nodes pulling from them. Upstreams which the policy denies are not recommended.
+type UpstreamRecommendations struct {
+	// LastUpdateTime is the time at which the recommended upstreams changed the last time.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+
+	// Upstreams are the recommended upstreams, the most pulled first.
+	// +kubebuilder:validation:MaxItems=10
+	// +optional
+	Upstreams []UpstreamRecommendation `json:"upstreams,omitempty"`
+}
+
+// UpstreamRecommendation is an upstream from which the Pods pull images, estimated from the images and the nodes of the Pods.
+type UpstreamRecommendation struct {
+	// Upstream is the normalized registry host, for example, `docker.io`.
+	Upstream string `json:"upstream"`
+
+	// ExampleImage is the image from the upstream which runs on the most nodes.
+	ExampleImage string `json:"exampleImage"`
+
+	// Images is the number of distinct images from the upstream.
+	Images int32 `json:"images"`
+
+	// Nodes is the number of nodes which run Pods with images from the upstream.
+	Nodes int32 `json:"nodes"`
+
+	// EstimatedPulls is the number of pulls needed for the images of the Pods, one per image and node.
+	EstimatedPulls int32 `json:"estimatedPulls"`
+
+	// EstimatedPullsPerDay is the number of these pulls caused by Pods which started within the last 24 hours,
+	// assuming that a node pulls an image when the first Pod using it starts on the node.
+	EstimatedPullsPerDay int32 `json:"estimatedPullsPerDay"`
 }
 
 func (s *RegistryCacheStatus) WithState(state State) *RegistryCacheStatus {
@@ -112,6 +203,25 @@ func (s *RegistryCacheStatus) WithInstallConditionStatus(status metav1.Condition
 	return s
 }
 
+// WithPolicyCondition records the result of the policy validation. It returns true if the condition changed.
+func (s *RegistryCacheStatus) WithPolicyCondition(policyErr error, objGeneration int64) bool {
+	condition := metav1.Condition{
+		Type:               ConditionTypePolicyValid,
+		Status:             metav1.ConditionTrue,
+		Reason:             ConditionReasonPolicyValid,
+		Message:            "Policy is valid",
+		ObservedGeneration: objGeneration,
+	}
+
+	if policyErr != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ConditionReasonPolicyInvalid
+		condition.Message = policyErr.Error()
+	}
+
+	return meta.SetStatusCondition(&s.Conditions, condition)
+}
+
 // +kubebuilder:object:root=true
 // RegistryCacheList contains a list of RegistryCache
 type RegistryCacheList struct {
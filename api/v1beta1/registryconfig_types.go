@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -55,17 +56,22 @@ type RegistryCacheConfigSpec struct {
 
 	// HTTP contains settings for the HTTP server that hosts the registry cache.
 	HTTP *HTTP `json:"http,omitempty"`
+
+	// Prewarm contains settings for pulling images through the registry cache ahead of the workloads which use them.
+	// +optional
+	Prewarm *Prewarm `json:"prewarm,omitempty"`
 }
 
 // Volume contains settings for the registry cache volume.
 type Volume struct {
 	// Size is the size of the registry cache volume.
 	// Defaults to 10Gi.
-	// This field is immutable.
+	// The size can be increased if the StorageClass of the volume allows volume expansion. It cannot be decreased.
 	// +optional
 	// +default="10Gi"
 	Size *resource.Quantity `json:"size,omitempty"`
 	// StorageClassName is the name of the StorageClass used by the registry cache volume.
+	// Defaults to the default StorageClass of the cluster on creation.
 	// This field is immutable.
 	// +optional
 	StorageClassName *string `json:"storageClassName,omitempty"`
@@ -97,6 +103,25 @@ type HTTP struct {
 	TLS bool `json:"tls,omitempty"`
 }
 
+// Prewarm contains settings for pulling images through the registry cache ahead of the workloads which use them.
+// The images are pulled by short-lived Jobs in the namespace of the RegistryCacheConfig, one per image.
+type Prewarm struct {
+	// Images is the list of images to pull through the registry cache, for example, `gcr.io/org/app:v1`.
+	// All images must be hosted by the upstream.
+	// +kubebuilder:validation:MaxItems=50
+	// +optional
+	Images []string `json:"images,omitempty"`
+	// WorkloadSelector selects the Deployments and StatefulSets in the namespace of the RegistryCacheConfig by their
+	// labels. The images of their containers and init containers which are hosted by the upstream are pulled as well.
+	// An empty selector selects all Deployments and StatefulSets of the namespace.
+	// +optional
+	WorkloadSelector *metav1.LabelSelector `json:"workloadSelector,omitempty"`
+	// Schedule is the schedule in the cron format, for example, `0 3 * * *`, at which all images are pulled again.
+	// Without a schedule, the images are pulled whenever the list of images changes.
+	// +optional
+	Schedule *string `json:"schedule,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 
@@ -131,6 +156,11 @@ type ConditionType string
 const (
 	ConditionTypeRegistryCacheValidated  ConditionType = "RegistryCacheValidated"
 	ConditionTypeRegistryCacheConfigured ConditionType = "RegistryCacheConfigured"
+	ConditionTypeVolumeResizeRequested   ConditionType = "VolumeResizeRequested"
+	ConditionTypeUpstreamReachable       ConditionType = "UpstreamReachable"
+	ConditionTypeCacheWorkloadReady      ConditionType = "CacheWorkloadReady"
+	ConditionTypeCredentialsSuspect      ConditionType = "CredentialsSuspect"
+	ConditionTypePullFailures            ConditionType = "PullFailures"
 )
 
 type ConditionReason string
@@ -139,6 +169,22 @@ const (
 	ConditionReasonRegistryCacheValidated        ConditionReason = "RegistryCacheValidated"
 	ConditionReasonRegistryCacheValidationFailed ConditionReason = "RegistryCacheValidationFailed"
 
+	ConditionReasonVolumeResizeRequested ConditionReason = "VolumeResizeRequested"
+	ConditionReasonVolumeSizeReached     ConditionReason = "VolumeSizeReached"
+
+	ConditionReasonUpstreamReachable   ConditionReason = "UpstreamReachable"
+	ConditionReasonUpstreamUnreachable ConditionReason = "UpstreamUnreachable"
+
+	ConditionReasonCacheWorkloadReady    ConditionReason = "CacheWorkloadReady"
+	ConditionReasonCacheWorkloadNotReady ConditionReason = "CacheWorkloadNotReady"
+	ConditionReasonCacheWorkloadNotFound ConditionReason = "CacheWorkloadNotFound"
+
+	ConditionReasonAuthenticationFailures   ConditionReason = "AuthenticationFailures"
+	ConditionReasonNoAuthenticationFailures ConditionReason = "NoAuthenticationFailures"
+
+	ConditionReasonImagePullsFailed    ConditionReason = "ImagePullsFailed"
+	ConditionReasonNoImagePullFailures ConditionReason = "NoImagePullFailures"
+
 	ConditionReasonRegistryCacheConfigured                       ConditionReason = "RegistryCacheConfigured"
 	ConditionReasonRegistryCacheExtensionConfigurationFailed     ConditionReason = "RegistryCacheExtensionConfigurationFailed"
 	ConditionReasonRegistryCacheGardenClusterConfigurationFailed ConditionReason = "RegistryCacheGardenClusterConfigurationFailed"
@@ -153,6 +199,219 @@ type RegistryCacheConfigStatus struct {
 
 	// List of status conditions to indicate the status of a ServiceInstance.
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// SecretReferenceName is the name of the Secret with the upstream registry credentials in the format expected by the registry cache.
+	// It equals spec.secretReferenceName, or names the immutable Secret derived from a referenced
+	// `kubernetes.io/dockerconfigjson` or `kubernetes.io/basic-auth` Secret.
+	// +optional
+	SecretReferenceName string `json:"secretReferenceName,omitempty"`
+
+	// Workload mirrors the health of the registry cache workload in the kube-system namespace.
+	// +optional
+	Workload *CacheWorkloadStatus `json:"workload,omitempty"`
+
+	// LogAnalysis counts the error entries in the logs of the registry cache Pods, classified by cause.
+	// +optional
+	LogAnalysis *LogAnalysis `json:"logAnalysis,omitempty"`
+
+	// Statistics summarizes the hits, misses, and transferred bytes of the registry cache.
+	// +optional
+	Statistics *CacheStatistics `json:"statistics,omitempty"`
+
+	// PullStatistics summarizes the durations and image sizes of recent successful image pulls of Pods from the upstream.
+	// +optional
+	PullStatistics *PullStatistics `json:"pullStatistics,omitempty"`
+
+	// PullFailures lists the most recent failed image pulls of Pods from the upstream, the latest first.
+	// +kubebuilder:validation:MaxItems=10
+	// +optional
+	PullFailures []PullFailure `json:"pullFailures,omitempty"`
+
+	// Prewarm reports the Jobs which pulled the images of spec.prewarm through the registry cache in the latest round.
+	// +optional
+	Prewarm *PrewarmStatus `json:"prewarm,omitempty"`
+}
+
+// PrewarmPhase is the phase of the Job which pulls an image through the registry cache.
+type PrewarmPhase string
+
+const (
+	PrewarmPhasePending   PrewarmPhase = "Pending"
+	PrewarmPhaseRunning   PrewarmPhase = "Running"
+	PrewarmPhaseSucceeded PrewarmPhase = "Succeeded"
+	PrewarmPhaseFailed    PrewarmPhase = "Failed"
+)
+
+// PrewarmStatus reports the latest round of image pulls through the registry cache.
+type PrewarmStatus struct {
+	// LastScheduleTime is the time at which the Jobs of the latest round were created.
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// NextScheduleTime is the time of the next round according to spec.prewarm.schedule.
+	// +optional
+	NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
+
+	// Images lists the result of the pull of every image in the latest round.
+	// +kubebuilder:validation:MaxItems=50
+	// +optional
+	Images []PrewarmImageStatus `json:"images,omitempty"`
+}
+
+// PrewarmImageStatus is the result of the pull of an image through the registry cache.
+type PrewarmImageStatus struct {
+	// Image is the image reference as listed in spec.prewarm.images or in the containers of the workloads.
+	Image string `json:"image"`
+
+	// JobName is the name of the Job which pulls the image.
+	JobName string `json:"jobName"`
+
+	// Phase is Pending, Running, Succeeded, or Failed.
+	// +kubebuilder:validation:Enum=Pending;Running;Succeeded;Failed
+	Phase PrewarmPhase `json:"phase"`
+
+	// Message is the reason why the pull failed.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// CompletionTime is the time at which the pull succeeded or finally failed.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// PullFailure is a failed image pull of a Pod, as reported by the kubelet in the Events of the Pod.
+type PullFailure struct {
+	// Namespace is the namespace of the Pod.
+	Namespace string `json:"namespace"`
+
+	// PodName is the name of the Pod.
+	PodName string `json:"podName"`
+
+	// Image is the image reference which the kubelet failed to pull.
+	Image string `json:"image"`
+
+	// Reason is ErrImagePull for a failed pull or ImagePullBackOff while the kubelet waits to retry the pull.
+	Reason string `json:"reason"`
+
+	// Message is the error which the kubelet reported.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Count is how often the pull failed.
+	Count int32 `json:"count"`
+
+	// LastTimestamp is the time at which the pull failed the last time.
+	LastTimestamp metav1.Time `json:"lastTimestamp"`
+}
+
+// CacheStatistics summarizes the proxy metrics of the registry cache. The registry cache counts since the start of its Pod,
+// so the values restart at zero when the Pod restarts.
+type CacheStatistics struct {
+	// Hits is the number of blob and manifest requests served from the cache.
+	Hits int64 `json:"hits"`
+
+	// Misses is the number of blob and manifest requests which were forwarded to the upstream.
+	Misses int64 `json:"misses"`
+
+	// HitRatioPercent is the share of the hits in all blob and manifest requests in percent.
+	// +optional
+	HitRatioPercent *int32 `json:"hitRatioPercent,omitempty"`
+
+	// ServedBytes is the amount of data which the registry cache served to the clients.
+	ServedBytes resource.Quantity `json:"servedBytes"`
+
+	// PulledBytes is the amount of data which the registry cache pulled from the upstream.
+	PulledBytes resource.Quantity `json:"pulledBytes"`
+
+	// SavedBytes is the amount of data which the clients did not pull from the upstream thanks to the cache.
+	SavedBytes resource.Quantity `json:"savedBytes"`
+
+	// LastScrapeTime is the time at which the metrics were scraped.
+	LastScrapeTime metav1.Time `json:"lastScrapeTime"`
+}
+
+// PullStatistics summarizes the successful image pulls which the kubelet reported in the Events of the Pods.
+// The pulls are collected since the start of the module, so the statistics restart when the module restarts.
+type PullStatistics struct {
+	// Window is the period of the summarized pulls, which ends at the time of the last update.
+	// It is shorter than the configured window until the pulls of the whole window were observed.
+	Window metav1.Duration `json:"window"`
+
+	// Pulls is the number of summarized image pulls.
+	Pulls int32 `json:"pulls"`
+
+	// DurationP50 is the median duration of the image pulls.
+	DurationP50 metav1.Duration `json:"durationP50"`
+
+	// DurationP95 is the 95th percentile of the durations of the image pulls.
+	DurationP95 metav1.Duration `json:"durationP95"`
+
+	// SizeP50 is the median size of the pulled images. Kubelets older than Kubernetes 1.30 do not report the image size.
+	// +optional
+	SizeP50 *resource.Quantity `json:"sizeP50,omitempty"`
+
+	// SizeP95 is the 95th percentile of the sizes of the pulled images.
+	// +optional
+	SizeP95 *resource.Quantity `json:"sizeP95,omitempty"`
+}
+
+// LogAnalysis counts the error entries which the registry cache logged while serving requests for the upstream.
+type LogAnalysis struct {
+	// Window is the period of the analyzed logs, which ends at the time of the analysis.
+	Window metav1.Duration `json:"window"`
+
+	// AuthFailures is the number of errors caused by rejected credentials, for example, 401 or 403 responses of the upstream.
+	AuthFailures int32 `json:"authFailures"`
+
+	// RateLimited is the number of errors caused by the rate limit of the upstream.
+	RateLimited int32 `json:"rateLimited"`
+
+	// UpstreamErrors is the number of errors caused by server errors of the upstream.
+	UpstreamErrors int32 `json:"upstreamErrors"`
+
+	// NotFound is the number of requests for images, manifests, or blobs which do not exist.
+	NotFound int32 `json:"notFound"`
+}
+
+// CacheWorkloadStatus describes the StatefulSet, Service, and PersistentVolumeClaim which the registry cache extension
+// creates for the upstream in the kube-system namespace.
+type CacheWorkloadStatus struct {
+	// StatefulSetName is the name of the StatefulSet which runs the registry cache.
+	StatefulSetName string `json:"statefulSetName"`
+
+	// Replicas is the number of desired replicas of the StatefulSet.
+	Replicas int32 `json:"replicas"`
+
+	// ReadyReplicas is the number of ready replicas of the StatefulSet.
+	ReadyReplicas int32 `json:"readyReplicas"`
+
+	// Restarts is the sum of the container restarts of the registry cache Pods.
+	Restarts int32 `json:"restarts"`
+
+	// ServiceName is the name of the Service in front of the registry cache.
+	ServiceName string `json:"serviceName"`
+
+	// ReadyEndpoints is the number of ready endpoints of the Service.
+	ReadyEndpoints int32 `json:"readyEndpoints"`
+
+	// VolumeClaimName is the name of the PersistentVolumeClaim of the cache volume.
+	VolumeClaimName string `json:"volumeClaimName"`
+
+	// VolumeClaimPhase is the phase of the PersistentVolumeClaim of the cache volume.
+	// +optional
+	VolumeClaimPhase corev1.PersistentVolumeClaimPhase `json:"volumeClaimPhase,omitempty"`
+
+	// VolumeCapacity is the capacity of the cache volume.
+	// +optional
+	VolumeCapacity *resource.Quantity `json:"volumeCapacity,omitempty"`
+
+	// VolumeUsed is the space used on the cache volume, as reported by the kubelet.
+	// +optional
+	VolumeUsed *resource.Quantity `json:"volumeUsed,omitempty"`
+
+	// VolumeUsagePercent is the used space in percent of the capacity of the cache volume.
+	// +optional
+	VolumeUsagePercent *int32 `json:"volumeUsagePercent,omitempty"`
 }
 
 func (rc *RegistryCacheConfig) RegistryCacheConfiguredUpdateStatusPendingUnknown(reason ConditionReason) {
@@ -167,6 +426,92 @@ func (rc *RegistryCacheConfig) RegistryCacheConfiguredUpdateStatusReady(reason C
 	rc.updateStatusReady(ConditionTypeRegistryCacheConfigured, reason, metav1.ConditionTrue)
 }
 
+func (rc *RegistryCacheConfig) RegistryCacheValidatedUpdateConditionTrue(reason ConditionReason) {
+	rc.updateCondition(ConditionTypeRegistryCacheValidated, reason, metav1.ConditionTrue, "")
+}
+
+func (rc *RegistryCacheConfig) RegistryCacheValidatedUpdateConditionFalse(reason ConditionReason, errorMessage string) {
+	rc.updateCondition(ConditionTypeRegistryCacheValidated, reason, metav1.ConditionFalse, errorMessage)
+}
+
+// VolumeResizeRequestedUpdateConditionTrue records that the cache volume is smaller than spec.volume.size.
+func (rc *RegistryCacheConfig) VolumeResizeRequestedUpdateConditionTrue(message string) {
+	rc.updateCondition(ConditionTypeVolumeResizeRequested, ConditionReasonVolumeResizeRequested, metav1.ConditionTrue, message)
+}
+
+// VolumeResizeRequestedUpdateConditionFalse records that the cache volume has the size of spec.volume.size.
+func (rc *RegistryCacheConfig) VolumeResizeRequestedUpdateConditionFalse(message string) {
+	rc.updateCondition(ConditionTypeVolumeResizeRequested, ConditionReasonVolumeSizeReached, metav1.ConditionFalse, message)
+}
+
+// UpstreamReachableUpdateConditionTrue records that the upstream registry responded on the /v2/ endpoint.
+func (rc *RegistryCacheConfig) UpstreamReachableUpdateConditionTrue(message string) {
+	rc.updateCondition(ConditionTypeUpstreamReachable, ConditionReasonUpstreamReachable, metav1.ConditionTrue, message)
+}
+
+// UpstreamReachableUpdateConditionFalse records that the upstream registry did not respond or responded with a server error.
+func (rc *RegistryCacheConfig) UpstreamReachableUpdateConditionFalse(message string) {
+	rc.updateCondition(ConditionTypeUpstreamReachable, ConditionReasonUpstreamUnreachable, metav1.ConditionFalse, message)
+}
+
+// CacheWorkloadReadyUpdateConditionTrue records that the StatefulSet, the Service, and the volume claim of the registry cache are ready.
+func (rc *RegistryCacheConfig) CacheWorkloadReadyUpdateConditionTrue(message string) {
+	rc.updateCondition(ConditionTypeCacheWorkloadReady, ConditionReasonCacheWorkloadReady, metav1.ConditionTrue, message)
+}
+
+// CacheWorkloadReadyUpdateConditionFalse records that the registry cache workload is missing or not ready.
+func (rc *RegistryCacheConfig) CacheWorkloadReadyUpdateConditionFalse(reason ConditionReason, message string) {
+	rc.updateCondition(ConditionTypeCacheWorkloadReady, reason, metav1.ConditionFalse, message)
+}
+
+// CredentialsSuspectUpdateConditionTrue records that the logs of the registry cache show rejected credentials.
+func (rc *RegistryCacheConfig) CredentialsSuspectUpdateConditionTrue(message string) {
+	rc.updateCondition(ConditionTypeCredentialsSuspect, ConditionReasonAuthenticationFailures, metav1.ConditionTrue, message)
+}
+
+// CredentialsSuspectUpdateConditionFalse records that the logs of the registry cache show no rejected credentials.
+func (rc *RegistryCacheConfig) CredentialsSuspectUpdateConditionFalse(message string) {
+	rc.updateCondition(ConditionTypeCredentialsSuspect, ConditionReasonNoAuthenticationFailures, metav1.ConditionFalse, message)
+}
+
+// PullFailuresUpdateConditionTrue records that Pods recently failed to pull images from the upstream.
+func (rc *RegistryCacheConfig) PullFailuresUpdateConditionTrue(message string) {
+	rc.updateCondition(ConditionTypePullFailures, ConditionReasonImagePullsFailed, metav1.ConditionTrue, message)
+}
+
+// PullFailuresUpdateConditionFalse records that no Pod recently failed to pull an image from the upstream.
+func (rc *RegistryCacheConfig) PullFailuresUpdateConditionFalse(message string) {
+	rc.updateCondition(ConditionTypePullFailures, ConditionReasonNoImagePullFailures, metav1.ConditionFalse, message)
+}
+
+// SetPrewarmStatus sets the status of the image pulls without changing the state, which is owned by the Kyma Control Plane.
+// The state is required by the schema, so it is initialized to Pending when the resource has no status yet.
+func (rc *RegistryCacheConfig) SetPrewarmStatus(status *PrewarmStatus) {
+	if rc.Status.State == "" {
+		rc.Status.State = PendingState
+	}
+
+	rc.Status.Prewarm = status
+}
+
+// updateCondition sets the condition without changing the state, which is owned by the Kyma Control Plane.
+// The state is required by the schema, so it is initialized to Pending when the resource has no status yet.
+func (rc *RegistryCacheConfig) updateCondition(conditionType ConditionType, reason ConditionReason, status metav1.ConditionStatus, message string) {
+	if rc.Status.State == "" {
+		rc.Status.State = PendingState
+	}
+
+	condition := metav1.Condition{
+		Type:               string(conditionType),
+		Reason:             string(reason),
+		Status:             status,
+		Message:            message,
+		ObservedGeneration: rc.Generation,
+	}
+
+	meta.SetStatusCondition(&rc.Status.Conditions, condition)
+}
+
 func (rc *RegistryCacheConfig) updateStatusPending(conditionType ConditionType, reason ConditionReason, status metav1.ConditionStatus) {
 	rc.Status.State = PendingState
 
@@ -25,6 +25,60 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CacheStatistics) DeepCopyInto(out *CacheStatistics) {
+	*out = *in
+	if in.HitRatioPercent != nil {
+		in, out := &in.HitRatioPercent, &out.HitRatioPercent
+		*out = new(int32)
+		**out = **in
+	}
+	out.ServedBytes = in.ServedBytes.DeepCopy()
+	out.PulledBytes = in.PulledBytes.DeepCopy()
+	out.SavedBytes = in.SavedBytes.DeepCopy()
+	in.LastScrapeTime.DeepCopyInto(&out.LastScrapeTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheStatistics.
+func (in *CacheStatistics) DeepCopy() *CacheStatistics {
+	if in == nil {
+		return nil
+	}
+	out := new(CacheStatistics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CacheWorkloadStatus) DeepCopyInto(out *CacheWorkloadStatus) {
+	*out = *in
+	if in.VolumeCapacity != nil {
+		in, out := &in.VolumeCapacity, &out.VolumeCapacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.VolumeUsed != nil {
+		in, out := &in.VolumeUsed, &out.VolumeUsed
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.VolumeUsagePercent != nil {
+		in, out := &in.VolumeUsagePercent, &out.VolumeUsagePercent
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheWorkloadStatus.
+func (in *CacheWorkloadStatus) DeepCopy() *CacheWorkloadStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CacheWorkloadStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GarbageCollection) DeepCopyInto(out *GarbageCollection) {
 	*out = *in
@@ -56,6 +110,101 @@ func (in *HTTP) DeepCopy() *HTTP {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogAnalysis) DeepCopyInto(out *LogAnalysis) {
+	*out = *in
+	out.Window = in.Window
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogAnalysis.
+func (in *LogAnalysis) DeepCopy() *LogAnalysis {
+	if in == nil {
+		return nil
+	}
+	out := new(LogAnalysis)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Prewarm) DeepCopyInto(out *Prewarm) {
+	*out = *in
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.WorkloadSelector != nil {
+		in, out := &in.WorkloadSelector, &out.WorkloadSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Prewarm.
+func (in *Prewarm) DeepCopy() *Prewarm {
+	if in == nil {
+		return nil
+	}
+	out := new(Prewarm)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrewarmImageStatus) DeepCopyInto(out *PrewarmImageStatus) {
+	*out = *in
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrewarmImageStatus.
+func (in *PrewarmImageStatus) DeepCopy() *PrewarmImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PrewarmImageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrewarmStatus) DeepCopyInto(out *PrewarmStatus) {
+	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextScheduleTime != nil {
+		in, out := &in.NextScheduleTime, &out.NextScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]PrewarmImageStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrewarmStatus.
+func (in *PrewarmStatus) DeepCopy() *PrewarmStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PrewarmStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Proxy) DeepCopyInto(out *Proxy) {
 	*out = *in
@@ -81,12 +230,56 @@ func (in *Proxy) DeepCopy() *Proxy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PullFailure) DeepCopyInto(out *PullFailure) {
+	*out = *in
+	in.LastTimestamp.DeepCopyInto(&out.LastTimestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullFailure.
+func (in *PullFailure) DeepCopy() *PullFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(PullFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PullStatistics) DeepCopyInto(out *PullStatistics) {
+	*out = *in
+	out.Window = in.Window
+	out.DurationP50 = in.DurationP50
+	out.DurationP95 = in.DurationP95
+	if in.SizeP50 != nil {
+		in, out := &in.SizeP50, &out.SizeP50
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.SizeP95 != nil {
+		in, out := &in.SizeP95, &out.SizeP95
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullStatistics.
+func (in *PullStatistics) DeepCopy() *PullStatistics {
+	if in == nil {
+		return nil
+	}
+	out := new(PullStatistics)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RegistryCache) DeepCopyInto(out *RegistryCache) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -200,6 +393,11 @@ func (in *RegistryCacheConfigSpec) DeepCopyInto(out *RegistryCacheConfigSpec) {
 		*out = new(HTTP)
 		**out = **in
 	}
+	if in.Prewarm != nil {
+		in, out := &in.Prewarm, &out.Prewarm
+		*out = new(Prewarm)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryCacheConfigSpec.
@@ -222,6 +420,38 @@ func (in *RegistryCacheConfigStatus) DeepCopyInto(out *RegistryCacheConfigStatus
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Workload != nil {
+		in, out := &in.Workload, &out.Workload
+		*out = new(CacheWorkloadStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LogAnalysis != nil {
+		in, out := &in.LogAnalysis, &out.LogAnalysis
+		*out = new(LogAnalysis)
+		**out = **in
+	}
+	if in.Statistics != nil {
+		in, out := &in.Statistics, &out.Statistics
+		*out = new(CacheStatistics)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PullStatistics != nil {
+		in, out := &in.PullStatistics, &out.PullStatistics
+		*out = new(PullStatistics)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PullFailures != nil {
+		in, out := &in.PullFailures, &out.PullFailures
+		*out = make([]PullFailure, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Prewarm != nil {
+		in, out := &in.Prewarm, &out.Prewarm
+		*out = new(PrewarmStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryCacheConfigStatus.
@@ -266,9 +496,54 @@ func (in *RegistryCacheList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryCachePolicy) DeepCopyInto(out *RegistryCachePolicy) {
+	*out = *in
+	if in.AllowedUpstreams != nil {
+		in, out := &in.AllowedUpstreams, &out.AllowedUpstreams
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeniedUpstreams != nil {
+		in, out := &in.DeniedUpstreams, &out.DeniedUpstreams
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxConfigs != nil {
+		in, out := &in.MaxConfigs, &out.MaxConfigs
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(StoragePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryCachePolicy.
+func (in *RegistryCachePolicy) DeepCopy() *RegistryCachePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryCachePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RegistryCacheSpec) DeepCopyInto(out *RegistryCacheSpec) {
 	*out = *in
+	if in.Policy != nil {
+		in, out := &in.Policy, &out.Policy
+		*out = new(RegistryCachePolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryCacheSpec.
@@ -291,6 +566,11 @@ func (in *RegistryCacheStatus) DeepCopyInto(out *RegistryCacheStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Recommendations != nil {
+		in, out := &in.Recommendations, &out.Recommendations
+		*out = new(UpstreamRecommendations)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryCacheStatus.
@@ -303,6 +583,72 @@ func (in *RegistryCacheStatus) DeepCopy() *RegistryCacheStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoragePolicy) DeepCopyInto(out *StoragePolicy) {
+	*out = *in
+	if in.MinVolumeSize != nil {
+		in, out := &in.MinVolumeSize, &out.MinVolumeSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaxVolumeSize != nil {
+		in, out := &in.MaxVolumeSize, &out.MaxVolumeSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Budget != nil {
+		in, out := &in.Budget, &out.Budget
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoragePolicy.
+func (in *StoragePolicy) DeepCopy() *StoragePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(StoragePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpstreamRecommendation) DeepCopyInto(out *UpstreamRecommendation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpstreamRecommendation.
+func (in *UpstreamRecommendation) DeepCopy() *UpstreamRecommendation {
+	if in == nil {
+		return nil
+	}
+	out := new(UpstreamRecommendation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpstreamRecommendations) DeepCopyInto(out *UpstreamRecommendations) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	if in.Upstreams != nil {
+		in, out := &in.Upstreams, &out.Upstreams
+		*out = make([]UpstreamRecommendation, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpstreamRecommendations.
+func (in *UpstreamRecommendations) DeepCopy() *UpstreamRecommendations {
+	if in == nil {
+		return nil
+	}
+	out := new(UpstreamRecommendations)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Volume) DeepCopyInto(out *Volume) {
 	*out = *in
@@ -21,13 +21,31 @@ import (
 	"crypto/fips140"
 	"crypto/tls"
 	"flag"
+	"fmt"
 	"os"
 	"path"
+	"time"
 
+	"github.com/kyma-project/registry-cache/internal/cachemetrics"
 	rccontroller "github.com/kyma-project/registry-cache/internal/controller"
+	"github.com/kyma-project/registry-cache/internal/distribution"
+	"github.com/kyma-project/registry-cache/internal/index"
+	"github.com/kyma-project/registry-cache/internal/loganalyzer"
+	"github.com/kyma-project/registry-cache/internal/prewarm"
+	"github.com/kyma-project/registry-cache/internal/prober"
+	"github.com/kyma-project/registry-cache/internal/pulllatency"
 	"github.com/kyma-project/registry-cache/internal/webhook/certificate"
 	"github.com/kyma-project/registry-cache/internal/webhook/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/webhook/validations"
+	"github.com/kyma-project/registry-cache/internal/workload"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -35,6 +53,7 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	webhook "github.com/kyma-project/registry-cache/internal/webhook/server"
+	webhookv1 "github.com/kyma-project/registry-cache/internal/webhook/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -55,6 +74,7 @@ const (
 	certDir                  = "/tmp/"
 	certificateAuthorityName = "ca.crt"
 	flagWebhookName          = "webhook-name"
+	flagMutatingWebhookName  = "mutating-webhook-name"
 	webhookServerKeyName     = "tls.key"
 	webhookServerCertName    = "tls.crt"
 	patchFieldManagerName    = "registry-cache-webhook"
@@ -73,6 +93,20 @@ func main() {
 	var enableHTTP2 bool
 	var tlsOpts []func(*tls.Config)
 	var webhookCfgName string
+	var mutatingWebhookCfgName string
+	var configRevalidationInterval time.Duration
+	var secretDeletionPolicy string
+	var verifyUpstreamCredentials bool
+	var upstreamProbeInterval time.Duration
+	var reportVolumeUsage bool
+	var logAnalysisInterval time.Duration
+	var cacheMetricsInterval time.Duration
+	var pullFailureWindow time.Duration
+	var pullLatencyInterval time.Duration
+	var pullLatencyWindow time.Duration
+	var prewarmImage string
+	var dnsMode string
+	var dnsOpts validations.DNSValidatorOptions
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -80,6 +114,45 @@ func main() {
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
 	flag.StringVar(&webhookCfgName, flagWebhookName, "registry-cache-validating-webhook-configuration", "The name of the validating webhook configuration to be updated.")
+	flag.StringVar(&mutatingWebhookCfgName, flagMutatingWebhookName, "registry-cache-mutating-webhook-configuration", "The name of the mutating webhook configuration to be updated.")
+	flag.DurationVar(&configRevalidationInterval, "config-revalidation-interval", rccontroller.DefaultRevalidationInterval,
+		"The interval in which RegistryCacheConfig resources are validated again after admission.")
+	flag.StringVar(&secretDeletionPolicy, "secret-deletion-policy", string(webhookv1.SecretDeletionPolicyDeny),
+		"How to handle the deletion of Secrets referenced by RegistryCacheConfig resources. One of: deny, warn.")
+	flag.BoolVar(&verifyUpstreamCredentials, "verify-upstream-credentials", false,
+		"If set, the credentials referenced by RegistryCacheConfig resources are verified against the upstream registry, through the proxy of the config. "+
+			"The webhook then sends the credentials of the users to their upstream registries and the token services which those name.")
+	flag.DurationVar(&upstreamProbeInterval, "upstream-probe-interval", prober.DefaultInterval,
+		"The interval in which the upstream registries are probed for the UpstreamReachable condition. Set to 0 to disable the probes.")
+	flag.BoolVar(&reportVolumeUsage, "report-volume-usage", false,
+		"If set, the usage of the cache volumes is read from the kubelet stats summary and reported in the RegistryCacheConfig status. "+
+			"Requires get on nodes/proxy, which the config/volume-usage component grants.")
+	flag.DurationVar(&logAnalysisInterval, "log-analysis-interval", loganalyzer.DefaultInterval,
+		"The interval in which the logs of the registry cache Pods are analyzed for credential and upstream errors. Set to 0 to disable the analysis.")
+	flag.DurationVar(&cacheMetricsInterval, "cache-metrics-interval", cachemetrics.DefaultInterval,
+		"The interval in which the metrics of the registry caches are scraped. Set to 0 to disable the scraping.")
+	flag.DurationVar(&pullFailureWindow, "pull-failure-window", rccontroller.DefaultPullFailureWindow,
+		"The time for which failed image pulls of Pods are reported in the RegistryCacheConfig status. Set to 0 to disable the reporting.")
+	flag.DurationVar(&pullLatencyInterval, "pull-latency-interval", pulllatency.DefaultInterval,
+		"The interval in which the Events of pulled images are read for the pull duration histograms. Set to 0 to disable the collection.")
+	flag.DurationVar(&pullLatencyWindow, "pull-latency-window", pulllatency.DefaultWindow,
+		"The period of the image pulls summarized in the RegistryCacheConfig status.")
+	flag.StringVar(&prewarmImage, "prewarm-image", "",
+		fmt.Sprintf("The image of the Jobs which pull the images of spec.prewarm through the registry caches, for example, %s. "+
+			"It must provide the crane CLI. The pre-warming is disabled if it is empty.", prewarm.CranePullerImage))
+	flag.StringVar(&dnsMode, "dns-mode", string(validations.DNSModeStrict),
+		"How to handle upstreams and remote URLs which are not DNS resolvable. One of: strict, warn, off.")
+	flag.Func("dns-nameservers", "Comma-separated list of nameservers in the <ip>[:<port>] format used for the DNS checks. "+
+		"The resolver of the system is used if not set.", func(value string) error {
+		nameservers, err := validations.ParseNameservers(value)
+		dnsOpts.Nameservers = nameservers
+		return err
+	})
+	flag.DurationVar(&dnsOpts.Timeout, "dns-timeout", validations.DefaultDNSTimeout, "The timeout of a single DNS lookup.")
+	flag.DurationVar(&dnsOpts.PositiveTTL, "dns-cache-ttl", validations.DefaultDNSPositiveTTL,
+		"The time a resolvable host is cached, 0 disables the caching.")
+	flag.DurationVar(&dnsOpts.NegativeTTL, "dns-negative-cache-ttl", validations.DefaultDNSNegativeTTL,
+		"The time a host which is not resolvable is cached, 0 disables the caching.")
 
 	opts := zap.Options{
 		Development: true,
@@ -89,6 +162,18 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	deletionPolicy, err := webhookv1.ParseSecretDeletionPolicy(secretDeletionPolicy)
+	if err != nil {
+		setupLog.Error(err, "invalid flag value", "flag", "secret-deletion-policy")
+		os.Exit(1)
+	}
+
+	parsedDNSMode, err := validations.ParseDNSMode(dnsMode)
+	if err != nil {
+		setupLog.Error(err, "invalid flag value", "flag", "dns-mode")
+		os.Exit(1)
+	}
+
 	if fips140.Enabled() {
 		setupLog.Info("FIPS mode is enabled")
 	} else {
@@ -147,6 +232,20 @@ func main() {
 				setupLog.Error(err, "unable to patch validating webhook configuration")
 				os.Exit(1)
 			}
+
+			updateMutatingCABundle := certificate.BuildUpdateMutatingCABundle(
+				context.Background(),
+				rtClient,
+				certificate.BuildUpdateCABundleOpts{
+					Name:         mutatingWebhookCfgName,
+					CABundle:     data,
+					FieldManager: patchFieldManagerName,
+				})
+
+			if err := retry.RetryOnConflict(retry.DefaultBackoff, updateMutatingCABundle); err != nil {
+				setupLog.Error(err, "unable to patch mutating webhook configuration")
+				os.Exit(1)
+			}
 		},
 	})
 
@@ -155,6 +254,25 @@ func main() {
 		Metrics: metricsserver.Options{
 			BindAddress: metricsAddr,
 		},
+		// Only the Warning Events of Pods are cached, which include the failed image pulls.
+		// Of the Secrets only the metadata is cached for the watches, their data is read from the API server when needed.
+		// Only the workloads of the registry caches are cached, the Pods of all namespaces are read from the API server in pages.
+		Cache: cache.Options{
+			ByObject: map[client.Object]cache.ByObject{
+				&corev1.Event{}:                 {Field: fields.SelectorFromSet(fields.Set{"type": corev1.EventTypeWarning, "involvedObject.kind": "Pod"})},
+				&batchv1.Job{}:                  {Label: prewarm.JobSelector()},
+				&corev1.Pod{}:                   {Namespaces: map[string]cache.Config{workload.Namespace: {}}},
+				&corev1.Service{}:               {Namespaces: map[string]cache.Config{workload.Namespace: {}}},
+				&discoveryv1.EndpointSlice{}:    {Namespaces: map[string]cache.Config{workload.Namespace: {}}},
+				&appsv1.StatefulSet{}:           {Namespaces: map[string]cache.Config{workload.Namespace: {}}},
+				&corev1.PersistentVolumeClaim{}: {Namespaces: map[string]cache.Config{workload.Namespace: {}}},
+			},
+		},
+		Client: client.Options{
+			Cache: &client.CacheOptions{
+				DisableFor: []client.Object{&corev1.Secret{}},
+			},
+		},
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         false,
@@ -176,11 +294,26 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := v1beta1.SetupRegistryCacheConfigWebhookWithManager(mgr, rtClient); err != nil {
+	if err := index.Setup(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		setupLog.Error(err, "unable to setup field indexes")
+		os.Exit(1)
+	}
+
+	dnsValidator := validations.NewDNSValidator(dnsOpts)
+	validatorOpts := []validations.Option{validations.WithDNSMode(parsedDNSMode)}
+	if verifyUpstreamCredentials {
+		validatorOpts = append(validatorOpts, validations.WithCredentialsVerifier(distribution.NewClient(nil)))
+	}
+	if err := v1beta1.SetupRegistryCacheConfigWebhookWithManager(mgr, mgr.GetClient(), dnsValidator, validatorOpts...); err != nil {
 		setupLog.Error(err, "unable to setup registry cache config webhook")
 		os.Exit(1)
 	}
 
+	if err := webhookv1.SetupSecretWebhookWithManager(mgr, mgr.GetClient(), deletionPolicy); err != nil {
+		setupLog.Error(err, "unable to setup secret webhook")
+		os.Exit(1)
+	}
+
 	regCacheReconciler := rccontroller.NewRegistryCacheReconciler(mgr, webhookServer.StartedChecker())
 
 	if err = regCacheReconciler.SetupWithManager(mgr); err != nil {
@@ -188,6 +321,70 @@ func main() {
 		os.Exit(1)
 	}
 
+	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create the clientset")
+		os.Exit(1)
+	}
+
+	var volumeUsage workload.VolumeUsageReader
+	if reportVolumeUsage {
+		volumeUsage = workload.NewKubeletVolumeUsage(clientset.CoreV1().RESTClient())
+	}
+
+	regCacheConfigReconciler := rccontroller.NewRegistryCacheConfigReconciler(mgr, dnsValidator, configRevalidationInterval, volumeUsage, validatorOpts...)
+
+	if err = regCacheConfigReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RegistryCacheConfig")
+		os.Exit(1)
+	}
+
+	if upstreamProbeInterval > 0 {
+		if err := mgr.Add(prober.New(mgr.GetClient(), mgr.GetEventRecorder("upstream-prober"), upstreamProbeInterval)); err != nil {
+			setupLog.Error(err, "unable to set up the upstream prober")
+			os.Exit(1)
+		}
+	}
+
+	if logAnalysisInterval > 0 {
+		if err := mgr.Add(loganalyzer.New(mgr.GetClient(), loganalyzer.NewPodLogReader(clientset), logAnalysisInterval)); err != nil {
+			setupLog.Error(err, "unable to set up the log analyzer")
+			os.Exit(1)
+		}
+	}
+
+	if cacheMetricsInterval > 0 {
+		if err := mgr.Add(cachemetrics.New(mgr.GetClient(), cacheMetricsInterval)); err != nil {
+			setupLog.Error(err, "unable to set up the cache metrics collector")
+			os.Exit(1)
+		}
+	}
+
+	if pullLatencyInterval > 0 {
+		if err := mgr.Add(pulllatency.New(mgr.GetClient(), mgr.GetAPIReader(), pullLatencyInterval, pullLatencyWindow)); err != nil {
+			setupLog.Error(err, "unable to set up the pull latency collector")
+			os.Exit(1)
+		}
+	}
+
+	if pullFailureWindow > 0 {
+		if err := index.SetupEvents(context.Background(), mgr.GetFieldIndexer()); err != nil {
+			setupLog.Error(err, "unable to setup event field indexes")
+			os.Exit(1)
+		}
+		if err = rccontroller.NewPullFailureReconciler(mgr, pullFailureWindow).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "PullFailure")
+			os.Exit(1)
+		}
+	}
+
+	if prewarmImage != "" {
+		if err = rccontroller.NewPrewarmReconciler(mgr, prewarmImage).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Prewarm")
+			os.Exit(1)
+		}
+	}
+
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", webhookServer.StartedChecker()); err != nil {
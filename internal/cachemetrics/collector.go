@@ -0,0 +1,268 @@
+package cachemetrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/constants"
+	"github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/workload"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// DefaultInterval is the interval in which the metrics of the registry caches are scraped.
+	DefaultInterval = time.Minute * 5
+
+	scrapeTimeout = time.Second * 10
+)
+
+// The proxy metrics of the distribution registry, each labelled by the type blob or manifest.
+const (
+	metricHits        = "registry_proxy_hits_total"
+	metricMisses      = "registry_proxy_misses_total"
+	metricPushedBytes = "registry_proxy_pushed_bytes_total"
+	metricPulledBytes = "registry_proxy_pulled_bytes_total"
+)
+
+var (
+	labels = []string{"config_namespace", "config", "upstream"}
+
+	hitsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "registry_cache_config_hits",
+		Help: "Number of blob and manifest requests served from the registry cache since the start of its Pod.",
+	}, labels)
+	missesGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "registry_cache_config_misses",
+		Help: "Number of blob and manifest requests forwarded to the upstream since the start of the registry cache Pod.",
+	}, labels)
+	hitRatioGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "registry_cache_config_hit_ratio",
+		Help: "Share of the hits in all blob and manifest requests of the registry cache, between 0 and 1.",
+	}, labels)
+	servedBytesGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "registry_cache_config_served_bytes",
+		Help: "Bytes served to the clients since the start of the registry cache Pod.",
+	}, labels)
+	pulledBytesGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "registry_cache_config_upstream_pulled_bytes",
+		Help: "Bytes pulled from the upstream since the start of the registry cache Pod.",
+	}, labels)
+
+	gauges = []*prometheus.GaugeVec{hitsGauge, missesGauge, hitRatioGauge, servedBytesGauge, pulledBytesGauge}
+)
+
+func init() {
+	for _, gauge := range gauges {
+		ctrlmetrics.Registry.MustRegister(gauge)
+	}
+}
+
+// Totals are the proxy metrics of a registry cache, summed over blobs and manifests.
+type Totals struct {
+	Hits        float64
+	Misses      float64
+	PushedBytes float64
+	PulledBytes float64
+}
+
+// Collector periodically scrapes the metrics endpoint of the registry cache Service of every RegistryCacheConfig,
+// exposes them as Prometheus series labelled by the config and the upstream, and summarizes them in status.statistics.
+// It implements the manager.Runnable interface.
+type Collector struct {
+	client     client.Client
+	httpClient *http.Client
+	endpoint   func(upstream string) string
+	interval   time.Duration
+	now        func() time.Time
+
+	mu       sync.Mutex
+	exported map[types.NamespacedName]string
+}
+
+// New constructs a Collector which scrapes the metrics every interval.
+func New(c client.Client, interval time.Duration) *Collector {
+	return &Collector{
+		client:     c,
+		httpClient: &http.Client{Timeout: scrapeTimeout},
+		endpoint:   ServiceEndpoint,
+		interval:   interval,
+		now:        time.Now,
+		exported:   map[types.NamespacedName]string{},
+	}
+}
+
+// ServiceEndpoint returns the URL of the metrics endpoint of the registry cache Service of the upstream.
+func ServiceEndpoint(upstream string) string {
+	return fmt.Sprintf("http://%s.%s.svc:%d/metrics", workload.ServiceName(upstream), workload.Namespace, constants.RegistryCacheDebugPort)
+}
+
+// Start scrapes the metrics until the context is cancelled.
+func (c *Collector) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, c.CollectAll, c.interval)
+	return nil
+}
+
+// NeedLeaderElection implements the LeaderElectionRunnable interface, so that only the leader updates the status.
+func (*Collector) NeedLeaderElection() bool {
+	return true
+}
+
+// CollectAll scrapes the metrics of the registry caches of all RegistryCacheConfigs once. Registry caches which cannot be
+// scraped, for example, because they are not running yet, keep their previous values. The series of deleted configs are removed.
+func (c *Collector) CollectAll(ctx context.Context) {
+	logger := log.FromContext(ctx).WithName("cache-metrics")
+
+	var configs v1beta1.RegistryCacheConfigList
+	if err := c.client.List(ctx, &configs); err != nil {
+		logger.Error(err, "failed to list registry cache configs")
+		return
+	}
+
+	existing := map[types.NamespacedName]bool{}
+	for _, config := range configs.Items {
+		key := client.ObjectKeyFromObject(&config)
+		existing[key] = true
+		if !config.DeletionTimestamp.IsZero() || config.Spec.Upstream == "" {
+			continue
+		}
+
+		totals, err := c.Scrape(ctx, c.endpoint(config.Spec.Upstream))
+		if err != nil {
+			logger.V(1).Info("failed to scrape the metrics of the registry cache", "namespace", config.Namespace, "name", config.Name, "error", err.Error())
+			continue
+		}
+
+		c.export(key, config.Spec.Upstream, totals)
+		if err := c.updateStatus(ctx, key, totals); err != nil {
+			logger.Error(err, "failed to update the cache statistics", "namespace", config.Namespace, "name", config.Name)
+		}
+	}
+
+	c.removeStale(existing)
+}
+
+// Scrape reads the proxy metrics from the metrics endpoint in the Prometheus text format.
+func (c *Collector) Scrape(ctx context.Context, endpoint string) (Totals, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Totals{}, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return Totals{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return Totals{}, fmt.Errorf("GET %s returned %d", endpoint, resp.StatusCode)
+	}
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return Totals{}, fmt.Errorf("failed to parse the metrics of %s: %w", endpoint, err)
+	}
+
+	sum := func(name string) float64 {
+		var total float64
+		if family, ok := families[name]; ok {
+			for _, metric := range family.GetMetric() {
+				total += metric.GetCounter().GetValue() + metric.GetUntyped().GetValue()
+			}
+		}
+		return total
+	}
+
+	return Totals{
+		Hits:        sum(metricHits),
+		Misses:      sum(metricMisses),
+		PushedBytes: sum(metricPushedBytes),
+		PulledBytes: sum(metricPulledBytes),
+	}, nil
+}
+
+func (c *Collector) export(key types.NamespacedName, upstream string, totals Totals) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if previous, ok := c.exported[key]; ok && previous != upstream {
+		deleteSeries(key)
+	}
+	c.exported[key] = upstream
+
+	values := []string{key.Namespace, key.Name, upstream}
+	hitsGauge.WithLabelValues(values...).Set(totals.Hits)
+	missesGauge.WithLabelValues(values...).Set(totals.Misses)
+	servedBytesGauge.WithLabelValues(values...).Set(totals.PushedBytes)
+	pulledBytesGauge.WithLabelValues(values...).Set(totals.PulledBytes)
+	if requests := totals.Hits + totals.Misses; requests > 0 {
+		hitRatioGauge.WithLabelValues(values...).Set(totals.Hits / requests)
+	}
+}
+
+func (c *Collector) removeStale(existing map[types.NamespacedName]bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.exported {
+		if !existing[key] {
+			deleteSeries(key)
+			delete(c.exported, key)
+		}
+	}
+}
+
+func deleteSeries(key types.NamespacedName) {
+	for _, gauge := range gauges {
+		gauge.DeletePartialMatch(prometheus.Labels{"config_namespace": key.Namespace, "config": key.Name})
+	}
+}
+
+// updateStatus writes the summary of the metrics to status.statistics of the latest version of the config.
+func (c *Collector) updateStatus(ctx context.Context, key types.NamespacedName, totals Totals) error {
+	var config v1beta1.RegistryCacheConfig
+	if err := c.client.Get(ctx, key, &config); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	original := config.DeepCopy()
+	config.Status.Statistics = Summarize(totals, c.now())
+
+	if err := c.client.Status().Patch(ctx, &config, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})); err != nil {
+		return fmt.Errorf("error while patching status: %w", err)
+	}
+	return nil
+}
+
+// Summarize converts the metrics to the summary in the status. The saved bytes are the bytes served to the clients
+// which were not pulled from the upstream.
+func Summarize(totals Totals, scrapeTime time.Time) *v1beta1.CacheStatistics {
+	statistics := &v1beta1.CacheStatistics{
+		Hits:           int64(totals.Hits),
+		Misses:         int64(totals.Misses),
+		ServedBytes:    *resource.NewQuantity(int64(totals.PushedBytes), resource.BinarySI),
+		PulledBytes:    *resource.NewQuantity(int64(totals.PulledBytes), resource.BinarySI),
+		SavedBytes:     *resource.NewQuantity(max(int64(totals.PushedBytes-totals.PulledBytes), 0), resource.BinarySI),
+		LastScrapeTime: metav1.NewTime(scrapeTime),
+	}
+	if requests := statistics.Hits + statistics.Misses; requests > 0 {
+		statistics.HitRatioPercent = ptr.To(int32(statistics.Hits * 100 / requests))
+	}
+
+	return statistics
+}
@@ -0,0 +1,160 @@
+package cachemetrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCollectAll(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/metrics.txt")
+	require.NoError(t, err)
+
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = w.Write(fixture)
+	}))
+	defer registry.Close()
+
+	scrapeTime := time.Date(2025, 3, 4, 9, 15, 0, 0, time.UTC)
+	newCollector := func(c client.Client, endpoints map[string]string) *Collector {
+		collector := New(c, DefaultInterval)
+		collector.endpoint = func(upstream string) string { return endpoints[upstream] }
+		collector.now = func() time.Time { return scrapeTime }
+		return collector
+	}
+
+	t.Run("summarizes the metrics in the status and exports them", func(t *testing.T) {
+		config := buildConfig("docker", "docker.io")
+		c := fixFakeClient(t, config)
+
+		newCollector(c, map[string]string{"docker.io": registry.URL + "/metrics"}).CollectAll(context.Background())
+
+		statistics := getConfig(t, c, "docker").Status.Statistics
+		require.NotNil(t, statistics)
+		require.Equal(t, int64(60), statistics.Hits)
+		require.Equal(t, int64(20), statistics.Misses)
+		require.Equal(t, ptr.To[int32](75), statistics.HitRatioPercent)
+		require.Equal(t, int64(12885032960), statistics.ServedBytes.Value())
+		require.Equal(t, int64(4295032832), statistics.PulledBytes.Value())
+		require.Equal(t, int64(8590000128), statistics.SavedBytes.Value())
+		require.True(t, statistics.LastScrapeTime.Equal(&metav1.Time{Time: scrapeTime}))
+
+		labels := prometheus.Labels{"config_namespace": "default", "config": "docker", "upstream": "docker.io"}
+		require.Equal(t, 60.0, gaugeValue(t, hitsGauge, labels))
+		require.Equal(t, 20.0, gaugeValue(t, missesGauge, labels))
+		require.Equal(t, 0.75, gaugeValue(t, hitRatioGauge, labels))
+		require.Equal(t, 12885032960.0, gaugeValue(t, servedBytesGauge, labels))
+		require.Equal(t, 4295032832.0, gaugeValue(t, pulledBytesGauge, labels))
+	})
+
+	t.Run("registry cache which cannot be scraped keeps the status", func(t *testing.T) {
+		unreachable := httptest.NewServer(http.NotFoundHandler())
+		unreachable.Close()
+
+		config := buildConfig("quay", "quay.io")
+		c := fixFakeClient(t, config)
+
+		newCollector(c, map[string]string{"quay.io": unreachable.URL + "/metrics"}).CollectAll(context.Background())
+
+		require.Nil(t, getConfig(t, c, "quay").Status.Statistics)
+		require.Zero(t, testCount(hitsGauge, prometheus.Labels{"config": "quay"}))
+	})
+
+	t.Run("series of deleted configs are removed", func(t *testing.T) {
+		config := buildConfig("ghcr", "ghcr.io")
+		c := fixFakeClient(t, config)
+		collector := newCollector(c, map[string]string{"ghcr.io": registry.URL + "/metrics"})
+
+		collector.CollectAll(context.Background())
+		require.Equal(t, 1, testCount(hitsGauge, prometheus.Labels{"config": "ghcr"}))
+
+		require.NoError(t, c.Delete(context.Background(), config))
+		collector.CollectAll(context.Background())
+		require.Zero(t, testCount(hitsGauge, prometheus.Labels{"config": "ghcr"}))
+	})
+}
+
+func TestSummarize(t *testing.T) {
+	statistics := Summarize(Totals{PushedBytes: 100, PulledBytes: 250}, time.Now())
+
+	require.Nil(t, statistics.HitRatioPercent, "no hit ratio is expected without requests")
+	require.True(t, statistics.SavedBytes.IsZero(), "saved bytes must not be negative")
+}
+
+func buildConfig(name, upstream string) *v1beta1.RegistryCacheConfig {
+	return &v1beta1.RegistryCacheConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       v1beta1.RegistryCacheConfigSpec{Upstream: upstream},
+	}
+}
+
+func fixFakeClient(t *testing.T, objects ...client.Object) client.Client {
+	s := runtime.NewScheme()
+	require.NoError(t, scheme.AddToScheme(s))
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	return fake.NewClientBuilder().
+		WithScheme(s).
+		WithObjects(objects...).
+		WithStatusSubresource(&v1beta1.RegistryCacheConfig{}).
+		Build()
+}
+
+func getConfig(t *testing.T, c client.Client, name string) v1beta1.RegistryCacheConfig {
+	var config v1beta1.RegistryCacheConfig
+	require.NoError(t, c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: name}, &config))
+	return config
+}
+
+func gaugeValue(t *testing.T, gauge *prometheus.GaugeVec, labels prometheus.Labels) float64 {
+	metric, err := gauge.GetMetricWith(labels)
+	require.NoError(t, err)
+
+	var value dto.Metric
+	require.NoError(t, metric.Write(&value))
+	return value.GetGauge().GetValue()
+}
+
+// testCount returns the number of series of the gauge which match the labels.
+func testCount(gauge *prometheus.GaugeVec, labels prometheus.Labels) int {
+	metrics := make(chan prometheus.Metric, 100)
+	gauge.Collect(metrics)
+	close(metrics)
+
+	count := 0
+	for metric := range metrics {
+		var value dto.Metric
+		if err := metric.Write(&value); err != nil {
+			continue
+		}
+		matches := 0
+		for _, pair := range value.GetLabel() {
+			if expected, ok := labels[pair.GetName()]; ok && expected == pair.GetValue() {
+				matches++
+			}
+		}
+		if matches == len(labels) {
+			count++
+		}
+	}
+	return count
+}
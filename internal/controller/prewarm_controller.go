@@ -0,0 +1,291 @@
+package rccontroller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/prewarm"
+	"github.com/robfig/cron/v3"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// workloadResyncInterval is the interval in which the images of the workloads selected by
+// spec.prewarm.workloadSelector are listed again.
+const workloadResyncInterval = time.Minute * 5
+
+// workloadListPageSize is the number of Deployments or StatefulSets read in one request.
+const workloadListPageSize = 500
+
+// PrewarmReconciler pulls the images of spec.prewarm through the registry cache of the upstream with one Job per image,
+// and reports the result of every pull in status.prewarm. The images are pulled again whenever the list of images
+// changes and at the times of spec.prewarm.schedule.
+type PrewarmReconciler struct {
+	client.Client
+	*runtime.Scheme
+	apiReader   client.Reader
+	pullerImage string
+	now         func() time.Time
+}
+
+// NewPrewarmReconciler constructs the reconciler which creates Jobs with the puller image. The namespace, Deployments,
+// and StatefulSets of a config are read with the API reader, so that they need not be cached.
+func NewPrewarmReconciler(mgr ctrl.Manager, pullerImage string) *PrewarmReconciler {
+	return &PrewarmReconciler{
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		apiReader:   mgr.GetAPIReader(),
+		pullerImage: pullerImage,
+		now:         time.Now,
+	}
+}
+
+func (r *PrewarmReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1beta1.RegistryCacheConfig{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&batchv1.Job{}).
+		Named("prewarm-controller").
+		Complete(r)
+}
+
+func (r *PrewarmReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	instance := v1beta1.RegistryCacheConfig{}
+	if err := r.Get(ctx, req.NamespacedName, &instance); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, fmt.Errorf("error while getting object: %w", err)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if !instance.GetDeletionTimestamp().IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	original := instance.DeepCopy()
+	if instance.Spec.Prewarm == nil || instance.Spec.Upstream == "" {
+		if instance.Status.Prewarm == nil {
+			return ctrl.Result{}, nil
+		}
+		instance.SetPrewarmStatus(nil)
+		return ctrl.Result{}, r.updateStatus(ctx, original, &instance)
+	}
+
+	images, err := r.images(ctx, &instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// The times in the status have a precision of seconds.
+	now := r.now().Truncate(time.Second)
+	schedule := r.schedule(ctx, &instance)
+
+	status := instance.Status.Prewarm.DeepCopy()
+	if status == nil {
+		status = &v1beta1.PrewarmStatus{}
+	}
+
+	if roundDue(status, images, schedule, now) {
+		status, err = r.startRound(ctx, &instance, images, now)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	} else if err := r.observeJobs(ctx, instance.Namespace, status); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	status.NextScheduleTime = nil
+	if schedule != nil {
+		status.NextScheduleTime = &metav1.Time{Time: schedule.Next(status.LastScheduleTime.Time)}
+	}
+
+	instance.SetPrewarmStatus(status)
+	if err := r.updateStatus(ctx, original, &instance); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var requeueAfter time.Duration
+	if status.NextScheduleTime != nil {
+		requeueAfter = max(status.NextScheduleTime.Sub(now), time.Second)
+	}
+	if instance.Spec.Prewarm.WorkloadSelector != nil && (requeueAfter == 0 || requeueAfter > workloadResyncInterval) {
+		requeueAfter = workloadResyncInterval
+	}
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// images returns the explicit images and the images of the Deployments and StatefulSets selected by
+// spec.prewarm.workloadSelector which are hosted by the upstream. Only the workloads in the namespace of the config
+// are read, so that a config cannot reveal the images of namespaces its creator may not see.
+func (r *PrewarmReconciler) images(ctx context.Context, instance *v1beta1.RegistryCacheConfig) ([]string, error) {
+	spec := instance.Spec.Prewarm
+	if spec.WorkloadSelector == nil {
+		return prewarm.Images(instance.Spec.Upstream, spec.Images, nil), nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(spec.WorkloadSelector)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing the workload selector: %w", err)
+	}
+
+	workloads, err := r.workloads(ctx, instance.Namespace, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	return prewarm.Images(instance.Spec.Upstream, spec.Images, workloads), nil
+}
+
+// workloads returns the Pod templates of the Deployments and StatefulSets in the namespace which match the selector.
+// They are listed in pages, so that a namespace with many workloads is not read in one request.
+func (r *PrewarmReconciler) workloads(ctx context.Context, namespace string, selector labels.Selector) ([]corev1.PodSpec, error) {
+	var workloads []corev1.PodSpec
+
+	continueToken := ""
+	for {
+		var deployments appsv1.DeploymentList
+		if err := r.apiReader.List(ctx, &deployments, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector},
+			client.Limit(workloadListPageSize), client.Continue(continueToken)); err != nil {
+			return nil, fmt.Errorf("error while listing deployments: %w", err)
+		}
+		for _, deployment := range deployments.Items {
+			workloads = append(workloads, deployment.Spec.Template.Spec)
+		}
+
+		continueToken = deployments.Continue
+		if continueToken == "" {
+			break
+		}
+	}
+
+	for {
+		var statefulSets appsv1.StatefulSetList
+		if err := r.apiReader.List(ctx, &statefulSets, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector},
+			client.Limit(workloadListPageSize), client.Continue(continueToken)); err != nil {
+			return nil, fmt.Errorf("error while listing statefulsets: %w", err)
+		}
+		for _, statefulSet := range statefulSets.Items {
+			workloads = append(workloads, statefulSet.Spec.Template.Spec)
+		}
+
+		continueToken = statefulSets.Continue
+		if continueToken == "" {
+			return workloads, nil
+		}
+	}
+}
+
+// schedule parses spec.prewarm.schedule. An invalid schedule, which the webhook rejects, is logged and ignored.
+func (r *PrewarmReconciler) schedule(ctx context.Context, instance *v1beta1.RegistryCacheConfig) cron.Schedule {
+	if instance.Spec.Prewarm.Schedule == nil {
+		return nil
+	}
+
+	schedule, err := cron.ParseStandard(*instance.Spec.Prewarm.Schedule)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "ignoring invalid prewarm schedule", "schedule", *instance.Spec.Prewarm.Schedule)
+		return nil
+	}
+
+	return schedule
+}
+
+// roundDue returns true if no round was started yet, if the images differ from the images of the latest round,
+// or if the schedule is due. A round missed while the controller was not running is started once.
+func roundDue(status *v1beta1.PrewarmStatus, images []string, schedule cron.Schedule, now time.Time) bool {
+	if status.LastScheduleTime == nil {
+		return true
+	}
+
+	previous := make([]string, 0, len(status.Images))
+	for _, image := range status.Images {
+		previous = append(previous, image.Image)
+	}
+	if !slices.Equal(previous, images) {
+		return true
+	}
+
+	return schedule != nil && !now.Before(schedule.Next(status.LastScheduleTime.Time))
+}
+
+// startRound creates a Job for every image. The Jobs of the previous round are left to finish and to expire.
+func (r *PrewarmReconciler) startRound(ctx context.Context, instance *v1beta1.RegistryCacheConfig, images []string, now time.Time) (*v1beta1.PrewarmStatus, error) {
+	status := &v1beta1.PrewarmStatus{LastScheduleTime: &metav1.Time{Time: now}}
+
+	for _, image := range images {
+		job := prewarm.NewJob(instance, image, prewarm.JobName(instance.Name, image, now), r.pullerImage)
+		if err := controllerutil.SetControllerReference(instance, job, r.Scheme); err != nil {
+			return nil, fmt.Errorf("error while setting the owner of job: %w", err)
+		}
+		if err := r.Create(ctx, job); client.IgnoreAlreadyExists(err) != nil {
+			return nil, fmt.Errorf("error while creating job for image %s: %w", image, err)
+		}
+
+		status.Images = append(status.Images, v1beta1.PrewarmImageStatus{
+			Image:   image,
+			JobName: job.Name,
+			Phase:   v1beta1.PrewarmPhasePending,
+		})
+	}
+
+	log.FromContext(ctx).Info("Started prewarming the registry cache", "namespace", instance.Namespace, "name", instance.Name, "images", len(images))
+
+	return status, nil
+}
+
+// observeJobs updates the phases of the unfinished pulls from their Jobs.
+func (r *PrewarmReconciler) observeJobs(ctx context.Context, namespace string, status *v1beta1.PrewarmStatus) error {
+	for i := range status.Images {
+		image := &status.Images[i]
+		if image.Phase == v1beta1.PrewarmPhaseSucceeded || image.Phase == v1beta1.PrewarmPhaseFailed {
+			continue
+		}
+
+		key := types.NamespacedName{Namespace: namespace, Name: image.JobName}
+		var job batchv1.Job
+		err := r.Get(ctx, key, &job)
+		if apierrors.IsNotFound(err) {
+			// the cache may not have observed a Job which was just created
+			err = r.apiReader.Get(ctx, key, &job)
+		}
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return fmt.Errorf("error while getting job %s: %w", image.JobName, err)
+			}
+			image.Phase, image.Message = v1beta1.PrewarmPhaseFailed, "the Job was deleted before it finished"
+			continue
+		}
+
+		image.Phase, image.Message, image.CompletionTime = prewarm.Status(&job)
+	}
+
+	return nil
+}
+
+// updateStatus patches the status only when it changed, so that the events of the Jobs cause no needless writes.
+func (r *PrewarmReconciler) updateStatus(ctx context.Context, original, instance *v1beta1.RegistryCacheConfig) error {
+	if equality.Semantic.DeepEqual(original.Status, instance.Status) {
+		return nil
+	}
+
+	if err := r.Status().Patch(ctx, instance, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})); err != nil {
+		return fmt.Errorf("error while patching status: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package rccontroller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rcapi "github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/prewarm"
+)
+
+var _ = Describe("Prewarm controller", func() {
+	Context("When a RegistryCacheConfig has spec.prewarm", func() {
+		const NamespaceName = "prewarm-workloads"
+		const OtherNamespaceName = "default"
+		ctx := context.Background()
+
+		It("Should create a Job for every image and report the pulls in the status", func() {
+			By("By creating a namespace with a Deployment")
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: NamespaceName}}
+			Expect(k8sClient.Create(ctx, namespace)).To(Succeed())
+
+			deployment := newDeploymentStub("app", NamespaceName, "ghcr.io/org/app:v2", "quay.io/org/sidecar:v1")
+			Expect(k8sClient.Create(ctx, deployment)).To(Succeed())
+
+			By("By creating a Deployment which is not selected, whose images must not be pulled")
+			unselectedDeployment := newDeploymentStub("unselected", NamespaceName, "ghcr.io/org/unselected:v1")
+			Expect(k8sClient.Create(ctx, unselectedDeployment)).To(Succeed())
+
+			By("By creating a Deployment in another namespace, whose images must not be pulled")
+			otherDeployment := newDeploymentStub("other", OtherNamespaceName, "ghcr.io/org/other:v1")
+			Expect(k8sClient.Create(ctx, otherDeployment)).To(Succeed())
+
+			By("By creating a RegistryCacheConfig CR")
+			config := newRegistryCacheConfigStub("config-prewarm", NamespaceName, rcapi.RegistryCacheConfigSpec{
+				Upstream: "ghcr.io",
+				Prewarm: &rcapi.Prewarm{
+					Images: []string{"ghcr.io/org/base:v1"},
+					WorkloadSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"app": "app"},
+					},
+					Schedule: ptr.To("0 3 * * *"),
+				},
+			})
+			Expect(k8sClient.Create(ctx, config)).To(Succeed())
+
+			jobs := func() []batchv1.Job {
+				var list batchv1.JobList
+				if err := k8sClient.List(ctx, &list, client.InNamespace(NamespaceName), client.MatchingLabels{prewarm.LabelConfigName: config.Name}); err != nil {
+					return nil
+				}
+				return list.Items
+			}
+
+			Eventually(jobs, time.Second*30, time.Millisecond*500).Should(ConsistOf(
+				And(
+					HaveField("ObjectMeta.Annotations", HaveKeyWithValue(prewarm.AnnotationImage, "ghcr.io/org/base:v1")),
+					HaveField("ObjectMeta.OwnerReferences", ConsistOf(HaveField("Name", config.Name))),
+					HaveField("Spec.Template.Spec.RestartPolicy", corev1.RestartPolicyNever),
+					HaveField("Spec.Template.Spec.Containers", ConsistOf(And(
+						HaveField("Image", prewarm.CranePullerImage),
+						HaveField("Args", Equal([]string{"pull", "--insecure", "registry-ghcr-io.kube-system.svc:5000/org/base:v1", "/dev/null"})),
+					))),
+				),
+				And(
+					HaveField("ObjectMeta.Annotations", HaveKeyWithValue(prewarm.AnnotationImage, "ghcr.io/org/app:v2")),
+					HaveField("Spec.Template.Spec.Containers", ConsistOf(
+						HaveField("Args", Equal([]string{"pull", "--insecure", "registry-ghcr-io.kube-system.svc:5000/org/app:v2", "/dev/null"})),
+					)),
+				),
+			))
+
+			Eventually(func() *rcapi.PrewarmStatus {
+				return getPrewarmStatus(ctx, client.ObjectKeyFromObject(config))
+			}, time.Second*30, time.Millisecond*500).Should(And(
+				Not(BeNil()),
+				HaveField("LastScheduleTime", Not(BeNil())),
+				HaveField("NextScheduleTime", Not(BeNil())),
+				HaveField("Images", ConsistOf(
+					And(HaveField("Image", "ghcr.io/org/base:v1"), HaveField("Phase", rcapi.PrewarmPhasePending)),
+					And(HaveField("Image", "ghcr.io/org/app:v2"), HaveField("Phase", rcapi.PrewarmPhasePending)),
+				)),
+			))
+
+			By("By starting the Job of an image")
+			job := jobs()[0]
+			job.Status.StartTime = ptr.To(metav1.Now())
+			job.Status.Active = 1
+			Expect(k8sClient.Status().Update(ctx, &job)).To(Succeed())
+
+			Eventually(func() []rcapi.PrewarmImageStatus {
+				status := getPrewarmStatus(ctx, client.ObjectKeyFromObject(config))
+				if status == nil {
+					return nil
+				}
+				return status.Images
+			}, time.Second*30, time.Millisecond*500).Should(ContainElement(And(
+				HaveField("JobName", job.Name),
+				HaveField("Phase", rcapi.PrewarmPhaseRunning),
+			)))
+
+			Expect(k8sClient.Delete(ctx, config)).To(Succeed())
+			Expect(k8sClient.DeleteAllOf(ctx, &batchv1.Job{}, client.InNamespace(NamespaceName), client.MatchingLabels{prewarm.LabelConfigName: config.Name})).To(Succeed())
+			Expect(k8sClient.Delete(ctx, deployment)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, unselectedDeployment)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, otherDeployment)).To(Succeed())
+		})
+	})
+})
+
+func newDeploymentStub(name, namespace string, images ...string) *appsv1.Deployment {
+	labels := map[string]string{"app": name}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+			},
+		},
+	}
+	for i, image := range images {
+		deployment.Spec.Template.Spec.Containers = append(deployment.Spec.Template.Spec.Containers,
+			corev1.Container{Name: fmt.Sprintf("c%d", i), Image: image})
+	}
+
+	return deployment
+}
+
+func getPrewarmStatus(ctx context.Context, key client.ObjectKey) *rcapi.PrewarmStatus {
+	registryCacheConfig := rcapi.RegistryCacheConfig{}
+	if err := k8sClient.Get(ctx, key, &registryCacheConfig); err != nil {
+		return nil
+	}
+
+	return registryCacheConfig.Status.Prewarm
+}
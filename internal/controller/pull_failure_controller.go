@@ -0,0 +1,157 @@
+package rccontroller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/index"
+	"github.com/kyma-project/registry-cache/internal/pullfailure"
+	"github.com/kyma-project/registry-cache/internal/upstream"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// DefaultPullFailureWindow is the time for which failed image pulls are reported in the RegistryCacheConfig status.
+	DefaultPullFailureWindow = time.Hour
+
+	// maxPullFailures is the maximum number of failed image pulls listed in the status.
+	maxPullFailures = 10
+)
+
+// PullFailureReconciler correlates the Events of Pods which failed to pull images with the RegistryCacheConfig
+// of the image upstream, and reports the recent failures in status.pullFailures and the PullFailures condition.
+type PullFailureReconciler struct {
+	client.Client
+	window time.Duration
+	now    func() time.Time
+}
+
+// NewPullFailureReconciler constructs the reconciler which reports the failed image pulls of the given window.
+func NewPullFailureReconciler(mgr ctrl.Manager, window time.Duration) *PullFailureReconciler {
+	return &PullFailureReconciler{
+		Client: mgr.GetClient(),
+		window: window,
+		now:    time.Now,
+	}
+}
+
+// SetupWithManager requires the index.RegistryCacheConfigUpstream field index of index.Setup and the index.EventImageUpstream
+// field index of index.SetupEvents to be registered.
+func (r *PullFailureReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1beta1.RegistryCacheConfig{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Event{}, handler.EnqueueRequestsFromMapFunc(r.configsForEvent), builder.WithPredicates(isPullFailureEvent)).
+		Named("pull-failure-controller").
+		Complete(r)
+}
+
+var isPullFailureEvent = predicate.NewPredicateFuncs(func(object client.Object) bool {
+	event, ok := object.(*corev1.Event)
+	if !ok {
+		return false
+	}
+	_, ok = pullfailure.FromEvent(event)
+	return ok
+})
+
+// configsForEvent maps the Event of a failed image pull to the RegistryCacheConfigs of the image upstream in the namespace
+// of the Event.
+func (r *PullFailureReconciler) configsForEvent(ctx context.Context, object client.Object) []reconcile.Request {
+	event, ok := object.(*corev1.Event)
+	if !ok {
+		return nil
+	}
+	failure, ok := pullfailure.FromEvent(event)
+	if !ok {
+		return nil
+	}
+
+	var configs v1beta1.RegistryCacheConfigList
+	if err := r.List(ctx, &configs, client.InNamespace(event.Namespace), client.MatchingFields{index.RegistryCacheConfigUpstream: failure.Upstream()}); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list registry cache configs for the image upstream", "upstream", failure.Upstream())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(configs.Items))
+	for _, config := range configs.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&config)})
+	}
+
+	return requests
+}
+
+func (r *PullFailureReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	instance := v1beta1.RegistryCacheConfig{}
+	if err := r.Get(ctx, req.NamespacedName, &instance); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, fmt.Errorf("error while getting object: %w", err)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if !instance.GetDeletionTimestamp().IsZero() || instance.Spec.Upstream == "" {
+		return ctrl.Result{}, nil
+	}
+
+	// Only the failures in the namespace of the config are reported, the status must not disclose the Pods of other tenants.
+	normalized := upstream.Normalize(instance.Spec.Upstream)
+	var events corev1.EventList
+	if err := r.List(ctx, &events, client.InNamespace(instance.Namespace), client.MatchingFields{index.EventImageUpstream: normalized}); err != nil {
+		return ctrl.Result{}, fmt.Errorf("error while listing the events of failed image pulls: %w", err)
+	}
+
+	now := r.now()
+	failures := pullfailure.Aggregate(events.Items, now.Add(-r.window))
+
+	original := instance.DeepCopy()
+	instance.Status.PullFailures = failures[:min(len(failures), maxPullFailures)]
+	if len(failures) == 0 {
+		instance.Status.PullFailures = nil
+		instance.PullFailuresUpdateConditionFalse(fmt.Sprintf("no pod failed to pull an image from %s within %s", normalized, r.window))
+	} else {
+		latest := failures[0]
+		instance.PullFailuresUpdateConditionTrue(fmt.Sprintf("failed image pulls from %s within %s: %d, latest: %s/%s %s: %s",
+			normalized, r.window, len(failures), latest.Namespace, latest.PodName, latest.Image, latestMessage(latest)))
+	}
+
+	if err := r.updateStatus(ctx, original, &instance); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if len(failures) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	// The failures are removed from the status when they leave the window, starting with the oldest one.
+	oldest := failures[len(failures)-1].LastTimestamp.Time
+	return ctrl.Result{RequeueAfter: max(oldest.Add(r.window).Sub(now), time.Second)}, nil
+}
+
+// updateStatus patches the status only when it changed, so that repeated Events of the same failures cause no writes.
+func (r *PullFailureReconciler) updateStatus(ctx context.Context, original, instance *v1beta1.RegistryCacheConfig) error {
+	if equality.Semantic.DeepEqual(original.Status, instance.Status) {
+		return nil
+	}
+
+	if err := r.Status().Patch(ctx, instance, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})); err != nil {
+		return fmt.Errorf("error while patching status: %w", err)
+	}
+	return nil
+}
+
+func latestMessage(failure v1beta1.PullFailure) string {
+	if failure.Message == "" {
+		return failure.Reason
+	}
+	return failure.Message
+}
@@ -0,0 +1,119 @@
+package rccontroller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rcapi "github.com/kyma-project/registry-cache/api/v1beta1"
+)
+
+var _ = Describe("Pull failure controller", func() {
+	Context("When a Pod fails to pull an image from the upstream", func() {
+		const NamespaceName = "default"
+		const ForeignNamespaceName = "pull-failures-foreign"
+		ctx := context.Background()
+
+		It("Should report the failed pulls in the RegistryCacheConfig status", func() {
+			By("By creating a RegistryCacheConfig CR")
+			config := newRegistryCacheConfigStub("config-pull-failures", NamespaceName, rcapi.RegistryCacheConfigSpec{
+				Upstream: "gcr.io",
+			})
+			Expect(k8sClient.Create(ctx, config)).To(Succeed())
+
+			Eventually(func() *metav1.Condition {
+				return getPullFailuresCondition(ctx, client.ObjectKeyFromObject(config))
+			}, time.Second*30, time.Millisecond*500).Should(And(
+				Not(BeNil()),
+				HaveField("Status", metav1.ConditionFalse),
+				HaveField("Reason", string(rcapi.ConditionReasonNoImagePullFailures)),
+			))
+
+			By("By creating the Event of a failed pull from the upstream")
+			failed := newPullFailureEventStub("app-failed", NamespaceName, "Failed",
+				`Failed to pull image "gcr.io/org/app:v1": rpc error: code = Unknown desc = 403 Forbidden`)
+			Expect(k8sClient.Create(ctx, failed)).To(Succeed())
+
+			By("By creating the Event of a failed pull from another upstream")
+			other := newPullFailureEventStub("app-other", NamespaceName, "Failed", `Failed to pull image "quay.io/org/app:v1": not found`)
+			Expect(k8sClient.Create(ctx, other)).To(Succeed())
+
+			By("By creating the Event of a failed pull from the upstream in another namespace, which must not be reported")
+			foreignNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ForeignNamespaceName}}
+			Expect(k8sClient.Create(ctx, foreignNamespace)).To(Succeed())
+			foreign := newPullFailureEventStub("app-foreign", ForeignNamespaceName, "Failed",
+				`Failed to pull image "gcr.io/tenant/secret-app:v1": rpc error: code = Unknown desc = 401 Unauthorized`)
+			Expect(k8sClient.Create(ctx, foreign)).To(Succeed())
+
+			Eventually(func() []rcapi.PullFailure {
+				registryCacheConfig := rcapi.RegistryCacheConfig{}
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(config), &registryCacheConfig); err != nil {
+					return nil
+				}
+				return registryCacheConfig.Status.PullFailures
+			}, time.Second*30, time.Millisecond*500).Should(ConsistOf(And(
+				HaveField("Namespace", NamespaceName),
+				HaveField("PodName", "app"),
+				HaveField("Image", "gcr.io/org/app:v1"),
+				HaveField("Reason", "ErrImagePull"),
+				HaveField("Message", "rpc error: code = Unknown desc = 403 Forbidden"),
+				HaveField("Count", int32(1)),
+			)))
+			Consistently(func() []rcapi.PullFailure {
+				registryCacheConfig := rcapi.RegistryCacheConfig{}
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(config), &registryCacheConfig); err != nil {
+					return nil
+				}
+				return registryCacheConfig.Status.PullFailures
+			}, time.Second*2, time.Millisecond*500).ShouldNot(ContainElement(HaveField("Namespace", ForeignNamespaceName)))
+			Expect(getPullFailuresCondition(ctx, client.ObjectKeyFromObject(config))).To(And(
+				HaveField("Status", metav1.ConditionTrue),
+				HaveField("Reason", string(rcapi.ConditionReasonImagePullsFailed)),
+				HaveField("Message", ContainSubstring("latest: default/app gcr.io/org/app:v1: rpc error")),
+			))
+
+			Expect(k8sClient.Delete(ctx, config)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, failed)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, other)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, foreign)).To(Succeed())
+		})
+	})
+})
+
+func newPullFailureEventStub(name, namespace, reason, message string) *corev1.Event {
+	now := metav1.Now()
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Namespace:  namespace,
+			Name:       "app",
+		},
+		Type:           corev1.EventTypeWarning,
+		Reason:         reason,
+		Message:        message,
+		Count:          1,
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Source:         corev1.EventSource{Component: "kubelet"},
+	}
+}
+
+func getPullFailuresCondition(ctx context.Context, key client.ObjectKey) *metav1.Condition {
+	registryCacheConfig := rcapi.RegistryCacheConfig{}
+	if err := k8sClient.Get(ctx, key, &registryCacheConfig); err != nil {
+		return nil
+	}
+
+	return meta.FindStatusCondition(registryCacheConfig.Status.Conditions, string(rcapi.ConditionTypePullFailures))
+}
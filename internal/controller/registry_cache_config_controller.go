@@ -0,0 +1,326 @@
+package rccontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/credentials"
+	"github.com/kyma-project/registry-cache/internal/index"
+	"github.com/kyma-project/registry-cache/internal/webhook/defaults"
+	"github.com/kyma-project/registry-cache/internal/webhook/validations"
+	"github.com/kyma-project/registry-cache/internal/workload"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	kevents "k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// DefaultRevalidationInterval is the interval in which RegistryCacheConfigs are validated again
+// to pick up changes of DNS, Secrets and other external state after admission.
+const DefaultRevalidationInterval = time.Minute * 10
+
+// RegistryCacheConfigReconciler re-runs the admission validations for RegistryCacheConfig resources periodically
+// and whenever they, a referenced Secret, or their registry cache workload change, and reports the result in the RegistryCacheValidated condition.
+type RegistryCacheConfigReconciler struct {
+	client.Client
+	*runtime.Scheme
+	kevents.EventRecorder
+	dnsValidator         validations.DNSValidator
+	revalidationInterval time.Duration
+	volumeUsage          workload.VolumeUsageReader
+	validatorOpts        []validations.Option
+}
+
+// NewRegistryCacheConfigReconciler constructs the reconciler. The volume usage reader is optional,
+// without it the usage of the cache volume is not reported.
+func NewRegistryCacheConfigReconciler(mgr ctrl.Manager, dnsValidator validations.DNSValidator, revalidationInterval time.Duration,
+	volumeUsage workload.VolumeUsageReader, validatorOpts ...validations.Option) *RegistryCacheConfigReconciler {
+	return &RegistryCacheConfigReconciler{
+		Client:               mgr.GetClient(),
+		Scheme:               mgr.GetScheme(),
+		EventRecorder:        mgr.GetEventRecorder("registry-cache-config-controller"),
+		dnsValidator:         dnsValidator,
+		revalidationInterval: revalidationInterval,
+		volumeUsage:          volumeUsage,
+		validatorOpts:        validatorOpts,
+	}
+}
+
+// SetupWithManager requires the index.RegistryCacheConfigSecretReferenceName field index to be registered. The Secrets are watched with their metadata only, so that their data is not cached.
+func (r *RegistryCacheConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1beta1.RegistryCacheConfig{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.configsForSecret), builder.OnlyMetadata).
+		Owns(&corev1.Secret{}, builder.OnlyMetadata).
+		Watches(&appsv1.StatefulSet{}, handler.EnqueueRequestsFromMapFunc(r.configsForWorkload), builder.WithPredicates(inWorkloadNamespace)).
+		Watches(&corev1.PersistentVolumeClaim{}, handler.EnqueueRequestsFromMapFunc(r.configsForWorkload), builder.WithPredicates(inWorkloadNamespace)).
+		Named("registry-cache-config-controller").
+		Complete(r)
+}
+
+// configsForSecret maps a Secret to the RegistryCacheConfigs referencing it in the same namespace.
+func (r *RegistryCacheConfigReconciler) configsForSecret(ctx context.Context, secret client.Object) []reconcile.Request {
+	var configs v1beta1.RegistryCacheConfigList
+	if err := r.List(ctx, &configs,
+		client.InNamespace(secret.GetNamespace()),
+		client.MatchingFields{index.RegistryCacheConfigSecretReferenceName: secret.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list registry cache configs referencing secret", "namespace", secret.GetNamespace(), "name", secret.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(configs.Items))
+	for _, config := range configs.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&config)})
+	}
+
+	return requests
+}
+
+var inWorkloadNamespace = predicate.NewPredicateFuncs(func(object client.Object) bool {
+	return object.GetNamespace() == workload.Namespace
+})
+
+// configsForWorkload maps the StatefulSet or the volume claim of a registry cache to the RegistryCacheConfigs of its upstream.
+func (r *RegistryCacheConfigReconciler) configsForWorkload(ctx context.Context, object client.Object) []reconcile.Request {
+	var configs v1beta1.RegistryCacheConfigList
+	if err := r.List(ctx, &configs); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list registry cache configs for the registry cache workload", "namespace", object.GetNamespace(), "name", object.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, config := range configs.Items {
+		if object.GetName() == workload.StatefulSetName(config.Spec.Upstream) || object.GetName() == workload.VolumeClaimName(config.Spec.Upstream) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&config)})
+		}
+	}
+
+	return requests
+}
+
+func (r *RegistryCacheConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	logger.Info("Reconciling RegistryCacheConfig resource", "namespace", req.Namespace, "name", req.Name)
+
+	instance := v1beta1.RegistryCacheConfig{}
+	if err := r.Get(ctx, req.NamespacedName, &instance); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, fmt.Errorf("error while getting object: %w", err)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if !instance.GetDeletionTimestamp().IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	original := instance.DeepCopy()
+	previous := meta.FindStatusCondition(original.Status.Conditions, string(v1beta1.ConditionTypeRegistryCacheValidated))
+
+	_, errs := validations.NewValidator(r.dnsValidator, r.Client, r.validatorOpts...).Revalidate(ctx, &instance)
+	if len(errs) == 0 {
+		instance.RegistryCacheValidatedUpdateConditionTrue(v1beta1.ConditionReasonRegistryCacheValidated)
+	} else {
+		instance.RegistryCacheValidatedUpdateConditionFalse(v1beta1.ConditionReasonRegistryCacheValidationFailed, errs.ToAggregate().Error())
+	}
+
+	if err := r.reconcileDerivedSecret(ctx, &instance); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.updateVolumeResizeCondition(ctx, &instance); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.updateWorkloadStatus(ctx, &instance); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.updateStatus(ctx, original, &instance); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	r.emitTransitionEvent(&instance, previous, errs)
+
+	return ctrl.Result{RequeueAfter: r.revalidationInterval}, nil
+}
+
+// reconcileDerivedSecret creates the Secret in the format expected by the registry cache for a referenced dockerconfigjson
+// or basic-auth Secret, and records the Secret to be used in status.secretReferenceName. Derived Secrets which are no longer
+// used are deleted. A missing or invalid referenced Secret leaves the status unchanged, the failure is reported
+// in the RegistryCacheValidated condition.
+func (r *RegistryCacheConfigReconciler) reconcileDerivedSecret(ctx context.Context, instance *v1beta1.RegistryCacheConfig) error {
+	if instance.Spec.SecretReferenceName == nil {
+		instance.Status.SecretReferenceName = ""
+		return r.deleteUnusedDerivedSecrets(ctx, instance, "")
+	}
+
+	var secret corev1.Secret
+	if err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: *instance.Spec.SecretReferenceName}, &secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("error while getting the referenced secret: %w", err)
+	}
+
+	if !credentials.NeedsDerivation(&secret) {
+		instance.Status.SecretReferenceName = secret.Name
+		return r.deleteUnusedDerivedSecrets(ctx, instance, "")
+	}
+
+	creds, err := credentials.Extract(&secret, instance.Spec.Upstream)
+	if err != nil {
+		return nil
+	}
+
+	derived := credentials.DerivedSecret(instance.Name, instance.Namespace, creds)
+	if err := controllerutil.SetControllerReference(instance, derived, r.Scheme); err != nil {
+		return fmt.Errorf("error while setting the owner of the derived secret: %w", err)
+	}
+	if err := r.Create(ctx, derived); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("error while creating the derived secret: %w", err)
+	}
+
+	instance.Status.SecretReferenceName = derived.Name
+	return r.deleteUnusedDerivedSecrets(ctx, instance, derived.Name)
+}
+
+// deleteUnusedDerivedSecrets deletes the Secrets derived for the RegistryCacheConfig except the one in use.
+func (r *RegistryCacheConfigReconciler) deleteUnusedDerivedSecrets(ctx context.Context, instance *v1beta1.RegistryCacheConfig, inUse string) error {
+	var secrets corev1.SecretList
+	if err := r.List(ctx, &secrets, client.InNamespace(instance.Namespace), client.MatchingLabels{credentials.LabelDerived: "true"}); err != nil {
+		return fmt.Errorf("error while listing derived secrets: %w", err)
+	}
+
+	for _, secret := range secrets.Items {
+		if secret.Name == inUse || !metav1.IsControlledBy(&secret, instance) {
+			continue
+		}
+		if err := r.Delete(ctx, &secret); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("error while deleting the derived secret %s: %w", secret.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// updateVolumeResizeCondition compares spec.volume.size with the capacity of the cache volume, so that the Kyma Control Plane
+// can expand the volume. The condition is not set before the registry cache created its volume claim.
+func (r *RegistryCacheConfigReconciler) updateVolumeResizeCondition(ctx context.Context, instance *v1beta1.RegistryCacheConfig) error {
+	var claim corev1.PersistentVolumeClaim
+	if err := r.Get(ctx, types.NamespacedName{Namespace: workload.Namespace, Name: workload.VolumeClaimName(instance.Spec.Upstream)}, &claim); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("error while getting the volume claim of the registry cache: %w", err)
+	}
+
+	requested := defaults.VolumeSize(instance.Spec)
+	capacity, ok := claim.Status.Capacity[corev1.ResourceStorage]
+	if !ok {
+		capacity = claim.Spec.Resources.Requests[corev1.ResourceStorage]
+	}
+
+	if requested.Cmp(capacity) > 0 {
+		instance.VolumeResizeRequestedUpdateConditionTrue(fmt.Sprintf("volume size %s is requested, the volume has %s", requested.String(), capacity.String()))
+		return nil
+	}
+
+	instance.VolumeResizeRequestedUpdateConditionFalse(fmt.Sprintf("the volume has %s", capacity.String()))
+	return nil
+}
+
+// updateWorkloadStatus mirrors the health of the registry cache StatefulSet, Service, and volume claim in kube-system
+// into status.workload and the CacheWorkloadReady condition.
+func (r *RegistryCacheConfigReconciler) updateWorkloadStatus(ctx context.Context, instance *v1beta1.RegistryCacheConfig) error {
+	status, problems, err := workload.Inspect(ctx, r.Client, r.volumeUsage, instance.Spec.Upstream)
+	if err != nil {
+		return err
+	}
+
+	instance.Status.Workload = status
+	switch {
+	case status == nil:
+		instance.CacheWorkloadReadyUpdateConditionFalse(v1beta1.ConditionReasonCacheWorkloadNotFound,
+			fmt.Sprintf("statefulset %s/%s does not exist", workload.Namespace, workload.StatefulSetName(instance.Spec.Upstream)))
+	case len(problems) > 0:
+		instance.CacheWorkloadReadyUpdateConditionFalse(v1beta1.ConditionReasonCacheWorkloadNotReady, strings.Join(problems, "; "))
+	default:
+		instance.CacheWorkloadReadyUpdateConditionTrue(fmt.Sprintf("statefulset %s/%s is ready", workload.Namespace, status.StatefulSetName))
+	}
+
+	return nil
+}
+
+// updateStatus patches the status only when it changed. The optimistic lock prevents overwriting
+// conditions which were set by the Kyma Control Plane in the meantime.
+func (r *RegistryCacheConfigReconciler) updateStatus(ctx context.Context, original, instance *v1beta1.RegistryCacheConfig) error {
+	if equality.Semantic.DeepEqual(original.Status, instance.Status) {
+		return nil
+	}
+
+	if err := r.Status().Patch(ctx, instance, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})); err != nil {
+		return fmt.Errorf("error while patching status: %w", err)
+	}
+	return nil
+}
+
+// emitTransitionEvent emits a Warning Event whenever the validation starts failing or fails for a different reason,
+// for example, when the referenced Secret was first deleted and then recreated with an invalid format.
+func (r *RegistryCacheConfigReconciler) emitTransitionEvent(instance *v1beta1.RegistryCacheConfig, previous *metav1.Condition, errs field.ErrorList) {
+	current := meta.FindStatusCondition(instance.Status.Conditions, string(v1beta1.ConditionTypeRegistryCacheValidated))
+	if current == nil {
+		return
+	}
+
+	if current.Status == metav1.ConditionFalse {
+		if previous != nil && previous.Status == current.Status && previous.Message == current.Message {
+			return
+		}
+		r.Eventf(instance, referencedSecret(instance, errs), "Warning", string(v1beta1.ConditionReasonRegistryCacheValidationFailed), "Validate", "%s", current.Message)
+		return
+	}
+
+	if previous != nil && previous.Status != current.Status {
+		r.Eventf(instance, nil, "Normal", string(v1beta1.ConditionReasonRegistryCacheValidated), "Validate", "validation passed")
+	}
+}
+
+// referencedSecret returns the Secret as the related object of the Event if it caused the validation failure.
+func referencedSecret(instance *v1beta1.RegistryCacheConfig, errs field.ErrorList) runtime.Object {
+	if instance.Spec.SecretReferenceName == nil {
+		return nil
+	}
+
+	secretPath := field.NewPath("spec").Child("secretReferenceName").String()
+	for _, err := range errs {
+		if err.Field == secretPath {
+			return &corev1.Secret{
+				TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      *instance.Spec.SecretReferenceName,
+					Namespace: instance.Namespace,
+				},
+			}
+		}
+	}
+
+	return nil
+}
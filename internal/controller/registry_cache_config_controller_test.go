@@ -0,0 +1,367 @@
+package rccontroller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rcapi "github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/credentials"
+	"github.com/kyma-project/registry-cache/internal/distribution"
+	"github.com/kyma-project/registry-cache/internal/workload"
+)
+
+var _ = Describe("RegistryCacheConfig controller", func() {
+	Context("When reconciling a resource", func() {
+		const NamespaceName = "default"
+		ctx := context.Background()
+
+		It("Should set the RegistryCacheValidated condition to true for a valid configuration", func() {
+			By("By creating a new RegistryCacheConfig CR")
+			config := newRegistryCacheConfigStub("valid-config", NamespaceName, rcapi.RegistryCacheConfigSpec{
+				Upstream: "quay.io",
+			})
+			Expect(k8sClient.Create(ctx, config)).To(Succeed())
+
+			By("By waiting for the RegistryCacheValidated condition")
+			Eventually(func() *metav1.Condition {
+				return getValidatedCondition(ctx, types.NamespacedName{Name: "valid-config", Namespace: NamespaceName})
+			}, time.Second*30, time.Millisecond*500).Should(And(
+				Not(BeNil()),
+				HaveField("Status", metav1.ConditionTrue),
+				HaveField("Reason", string(rcapi.ConditionReasonRegistryCacheValidated)),
+				HaveField("ObservedGeneration", int64(1)),
+			))
+
+			By("By checking that the state is initialized")
+			registryCacheConfig := rcapi.RegistryCacheConfig{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "valid-config", Namespace: NamespaceName}, &registryCacheConfig)).To(Succeed())
+			Expect(registryCacheConfig.Status.State).To(Equal(rcapi.PendingState))
+
+			Expect(k8sClient.Delete(ctx, &registryCacheConfig)).To(Succeed())
+		})
+
+		It("Should set the RegistryCacheValidated condition to false when the referenced Secret does not exist", func() {
+			By("By creating a new RegistryCacheConfig CR referencing a missing Secret")
+			config := newRegistryCacheConfigStub("config-missing-secret", NamespaceName, rcapi.RegistryCacheConfigSpec{
+				Upstream:            "ghcr.io",
+				SecretReferenceName: ptr.To("missing-secret"),
+			})
+			Expect(k8sClient.Create(ctx, config)).To(Succeed())
+
+			By("By waiting for the RegistryCacheValidated condition")
+			Eventually(func() *metav1.Condition {
+				return getValidatedCondition(ctx, types.NamespacedName{Name: "config-missing-secret", Namespace: NamespaceName})
+			}, time.Second*30, time.Millisecond*500).Should(And(
+				Not(BeNil()),
+				HaveField("Status", metav1.ConditionFalse),
+				HaveField("Reason", string(rcapi.ConditionReasonRegistryCacheValidationFailed)),
+				HaveField("Message", ContainSubstring("secret missing-secret does not exist")),
+				HaveField("ObservedGeneration", int64(1)),
+			))
+
+			Expect(k8sClient.Delete(ctx, config)).To(Succeed())
+		})
+
+		It("Should set the RegistryCacheValidated condition to false when the referenced Secret is deleted", func() {
+			By("By creating a valid upstream Secret")
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "upstream-credentials",
+					Namespace: NamespaceName,
+				},
+				Immutable: ptr.To(true),
+				Data: map[string][]byte{
+					"username": []byte("user"),
+					"password": []byte("pass"),
+				},
+			}
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+
+			By("By creating a new RegistryCacheConfig CR referencing the Secret")
+			config := newRegistryCacheConfigStub("config-deleted-secret", NamespaceName, rcapi.RegistryCacheConfigSpec{
+				Upstream:            "eu.gcr.io",
+				SecretReferenceName: ptr.To("upstream-credentials"),
+			})
+			Expect(k8sClient.Create(ctx, config)).To(Succeed())
+
+			key := types.NamespacedName{Name: "config-deleted-secret", Namespace: NamespaceName}
+			Eventually(func() *metav1.Condition {
+				return getValidatedCondition(ctx, key)
+			}, time.Second*30, time.Millisecond*500).Should(And(
+				Not(BeNil()),
+				HaveField("Status", metav1.ConditionTrue),
+			))
+
+			By("By deleting the Secret")
+			Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+
+			By("By waiting for the RegistryCacheValidated condition to fail")
+			Eventually(func() *metav1.Condition {
+				return getValidatedCondition(ctx, key)
+			}, time.Second*30, time.Millisecond*500).Should(And(
+				Not(BeNil()),
+				HaveField("Status", metav1.ConditionFalse),
+				HaveField("Reason", string(rcapi.ConditionReasonRegistryCacheValidationFailed)),
+				HaveField("Message", ContainSubstring("secret upstream-credentials does not exist")),
+			))
+
+			By("By checking that a Warning Event was emitted")
+			Eventually(func() []eventsv1.Event {
+				var events eventsv1.EventList
+				if err := k8sClient.List(ctx, &events, client.InNamespace(NamespaceName)); err != nil {
+					return nil
+				}
+				return events.Items
+			}, time.Second*30, time.Millisecond*500).Should(ContainElement(And(
+				HaveField("Regarding.Name", "config-deleted-secret"),
+				HaveField("Related.Name", "upstream-credentials"),
+				HaveField("Type", "Warning"),
+				HaveField("Reason", string(rcapi.ConditionReasonRegistryCacheValidationFailed)),
+			)))
+
+			Expect(k8sClient.Delete(ctx, config)).To(Succeed())
+		})
+
+		It("Should set the VolumeResizeRequested condition when the volume is smaller than the requested size", func() {
+			By("By creating the volume claim of the registry cache")
+			claim := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      workload.VolumeClaimName("registry.k8s.io"),
+					Namespace: workload.Namespace,
+				},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, claim)).To(Succeed())
+			claim.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}
+			Expect(k8sClient.Status().Update(ctx, claim)).To(Succeed())
+
+			By("By creating a RegistryCacheConfig CR requesting a larger volume")
+			config := newRegistryCacheConfigStub("config-resize", NamespaceName, rcapi.RegistryCacheConfigSpec{
+				Upstream: "registry.k8s.io",
+				Volume:   &rcapi.Volume{Size: ptr.To(resource.MustParse("20Gi"))},
+			})
+			Expect(k8sClient.Create(ctx, config)).To(Succeed())
+
+			By("By waiting for the VolumeResizeRequested condition")
+			Eventually(func() *metav1.Condition {
+				registryCacheConfig := rcapi.RegistryCacheConfig{}
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(config), &registryCacheConfig); err != nil {
+					return nil
+				}
+				return meta.FindStatusCondition(registryCacheConfig.Status.Conditions, string(rcapi.ConditionTypeVolumeResizeRequested))
+			}, time.Second*30, time.Millisecond*500).Should(And(
+				Not(BeNil()),
+				HaveField("Status", metav1.ConditionTrue),
+				HaveField("Reason", string(rcapi.ConditionReasonVolumeResizeRequested)),
+				HaveField("Message", "volume size 20Gi is requested, the volume has 10Gi"),
+			))
+
+			Expect(k8sClient.Delete(ctx, config)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, claim)).To(Succeed())
+		})
+
+		It("Should mirror the health of the registry cache workload", func() {
+			By("By creating a RegistryCacheConfig CR without a registry cache workload")
+			config := newRegistryCacheConfigStub("config-workload", NamespaceName, rcapi.RegistryCacheConfigSpec{
+				Upstream: "ghcr.io",
+			})
+			Expect(k8sClient.Create(ctx, config)).To(Succeed())
+
+			Eventually(func() *metav1.Condition {
+				return getWorkloadCondition(ctx, client.ObjectKeyFromObject(config))
+			}, time.Second*30, time.Millisecond*500).Should(And(
+				Not(BeNil()),
+				HaveField("Status", metav1.ConditionFalse),
+				HaveField("Reason", string(rcapi.ConditionReasonCacheWorkloadNotFound)),
+			))
+
+			By("By creating the StatefulSet of the registry cache")
+			labels := map[string]string{"app": workload.StatefulSetName("ghcr.io")}
+			statefulSet := &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      workload.StatefulSetName("ghcr.io"),
+					Namespace: workload.Namespace,
+				},
+				Spec: appsv1.StatefulSetSpec{
+					Replicas: ptr.To[int32](1),
+					Selector: &metav1.LabelSelector{MatchLabels: labels},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: labels},
+						Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "registry-cache", Image: "registry:3"}}},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, statefulSet)).To(Succeed())
+
+			Eventually(func() *metav1.Condition {
+				return getWorkloadCondition(ctx, client.ObjectKeyFromObject(config))
+			}, time.Second*30, time.Millisecond*500).Should(And(
+				Not(BeNil()),
+				HaveField("Status", metav1.ConditionFalse),
+				HaveField("Reason", string(rcapi.ConditionReasonCacheWorkloadNotReady)),
+				HaveField("Message", ContainSubstring("statefulset kube-system/registry-ghcr-io has 0/1 ready replicas")),
+			))
+
+			By("By marking the StatefulSet as ready")
+			statefulSet.Status.Replicas = 1
+			statefulSet.Status.ReadyReplicas = 1
+			Expect(k8sClient.Status().Update(ctx, statefulSet)).To(Succeed())
+
+			Eventually(func() *rcapi.CacheWorkloadStatus {
+				registryCacheConfig := rcapi.RegistryCacheConfig{}
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(config), &registryCacheConfig); err != nil {
+					return nil
+				}
+				return registryCacheConfig.Status.Workload
+			}, time.Second*30, time.Millisecond*500).Should(And(
+				Not(BeNil()),
+				HaveField("StatefulSetName", "registry-ghcr-io"),
+				HaveField("ReadyReplicas", int32(1)),
+				HaveField("ServiceName", "registry-ghcr-io"),
+			))
+			Expect(getWorkloadCondition(ctx, client.ObjectKeyFromObject(config))).To(And(
+				HaveField("Status", metav1.ConditionFalse),
+				HaveField("Message", Not(ContainSubstring("ready replicas"))),
+				HaveField("Message", ContainSubstring("service kube-system/registry-ghcr-io does not exist")),
+			))
+
+			Expect(k8sClient.Delete(ctx, config)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, statefulSet)).To(Succeed())
+		})
+
+		It("Should set the RegistryCacheValidated condition to false when the referenced Secret is updated", func() {
+			By("By creating a dockerconfigjson Secret with credentials for the upstream")
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "updated-pull-secret",
+					Namespace: NamespaceName,
+				},
+				Type: corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{
+					corev1.DockerConfigJsonKey: []byte(`{"auths":{"registry.gitlab.com":{"username":"user","password":"password"}}}`),
+				},
+			}
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+
+			By("By creating a new RegistryCacheConfig CR referencing the Secret")
+			config := newRegistryCacheConfigStub("config-updated-secret", NamespaceName, rcapi.RegistryCacheConfigSpec{
+				Upstream:            "registry.gitlab.com",
+				SecretReferenceName: ptr.To(secret.Name),
+			})
+			Expect(k8sClient.Create(ctx, config)).To(Succeed())
+
+			key := client.ObjectKeyFromObject(config)
+			Eventually(func() *metav1.Condition {
+				return getValidatedCondition(ctx, key)
+			}, time.Second*30, time.Millisecond*500).Should(And(
+				Not(BeNil()),
+				HaveField("Status", metav1.ConditionTrue),
+			))
+
+			By("By replacing the credentials with ones for another registry")
+			secret.Data[corev1.DockerConfigJsonKey] = []byte(`{"auths":{"quay.io":{"username":"user","password":"password"}}}`)
+			Expect(k8sClient.Update(ctx, secret)).To(Succeed())
+
+			By("By waiting for the RegistryCacheValidated condition to fail before the next revalidation")
+			Eventually(func() *metav1.Condition {
+				return getValidatedCondition(ctx, key)
+			}, time.Second*30, time.Millisecond*500).Should(And(
+				Not(BeNil()),
+				HaveField("Status", metav1.ConditionFalse),
+				HaveField("Reason", string(rcapi.ConditionReasonRegistryCacheValidationFailed)),
+				HaveField("Message", ContainSubstring("no credentials for upstream registry.gitlab.com")),
+			))
+
+			Expect(k8sClient.Delete(ctx, config)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+		})
+
+		It("Should derive a Secret in the canonical format from a basic-auth Secret", func() {
+			By("By creating a basic-auth Secret")
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "basic-auth-secret",
+					Namespace: NamespaceName,
+				},
+				Type: corev1.SecretTypeBasicAuth,
+				Data: map[string][]byte{
+					corev1.BasicAuthUsernameKey: []byte("user"),
+					corev1.BasicAuthPasswordKey: []byte("password"),
+				},
+			}
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+
+			By("By creating a RegistryCacheConfig CR referencing the Secret")
+			config := newRegistryCacheConfigStub("config-basic-auth", NamespaceName, rcapi.RegistryCacheConfigSpec{
+				Upstream:            "gcr.io",
+				SecretReferenceName: ptr.To(secret.Name),
+			})
+			Expect(k8sClient.Create(ctx, config)).To(Succeed())
+
+			By("By waiting for the derived Secret to be recorded in the status")
+			derivedName := credentials.DerivedSecretName(config.Name, distribution.Credentials{Username: "user", Password: "password"})
+			Eventually(func() string {
+				registryCacheConfig := rcapi.RegistryCacheConfig{}
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(config), &registryCacheConfig); err != nil {
+					return ""
+				}
+				return registryCacheConfig.Status.SecretReferenceName
+			}, time.Second*30, time.Millisecond*500).Should(Equal(derivedName))
+
+			derived := corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: NamespaceName, Name: derivedName}, &derived)).To(Succeed())
+			Expect(derived.Type).To(Equal(corev1.SecretTypeOpaque))
+			Expect(derived.Immutable).To(Equal(ptr.To(true)))
+			Expect(derived.Data).To(Equal(map[string][]byte{"username": []byte("user"), "password": []byte("password")}))
+			Expect(metav1.IsControlledBy(&derived, config)).To(BeTrue())
+
+			Expect(k8sClient.Delete(ctx, config)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+		})
+	})
+})
+
+func newRegistryCacheConfigStub(name, namespace string, spec rcapi.RegistryCacheConfigSpec) *rcapi.RegistryCacheConfig {
+	return &rcapi.RegistryCacheConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: spec,
+	}
+}
+
+func getValidatedCondition(ctx context.Context, key types.NamespacedName) *metav1.Condition {
+	registryCacheConfig := rcapi.RegistryCacheConfig{}
+	if err := k8sClient.Get(ctx, key, &registryCacheConfig); err != nil {
+		return nil
+	}
+
+	return meta.FindStatusCondition(registryCacheConfig.Status.Conditions, string(rcapi.ConditionTypeRegistryCacheValidated))
+}
+
+func getWorkloadCondition(ctx context.Context, key types.NamespacedName) *metav1.Condition {
+	registryCacheConfig := rcapi.RegistryCacheConfig{}
+	if err := k8sClient.Get(ctx, key, &registryCacheConfig); err != nil {
+		return nil
+	}
+
+	return meta.FindStatusCondition(registryCacheConfig.Status.Conditions, string(rcapi.ConditionTypeCacheWorkloadReady))
+}
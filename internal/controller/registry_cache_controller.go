@@ -3,11 +3,18 @@ package rccontroller
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/policy"
+	"github.com/kyma-project/registry-cache/internal/recommendation"
+	"github.com/kyma-project/registry-cache/internal/upstream"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	kevents "k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -24,19 +31,32 @@ const (
 	fieldOwner            = "registry-cache.kyma-project.io/owner"
 )
 
+// recommendationInterval is the minimum interval between inventories of the images for the recommended upstreams.
+const recommendationInterval = time.Minute * 5
+
+// podListPageSize is the number of Pods read in one request of the inventory.
+const podListPageSize = 500
+
 type RegistryCacheReconciler struct {
 	client.Client
 	*runtime.Scheme
 	kevents.EventRecorder
 	healthz.Checker
+	apiReader client.Reader
+
+	// lastInventory is the time of the last inventory of the images. The recommendations are updated only when they
+	// change, so the time of their last update does not tell when the images were inventoried.
+	lastInventory time.Time
 }
 
+// NewRegistryCacheReconciler constructs the reconciler. The Pods are listed with the API reader, so that they need not be cached.
 func NewRegistryCacheReconciler(mgr ctrl.Manager, check healthz.Checker) *RegistryCacheReconciler {
 	return &RegistryCacheReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
 		EventRecorder: mgr.GetEventRecorder("registry-cache-controller"),
 		Checker:       check,
+		apiReader:     mgr.GetAPIReader(),
 	}
 }
 
@@ -111,7 +131,22 @@ func (r *RegistryCacheReconciler) handleReadyState(ctx context.Context, objectIn
 		r.Eventf(objectInstance, nil, "Warning", "Webhook server not ready", "WebhookNotReady", err.Error())
 		return r.setInstanceStatus(ctx, objectInstance, v1beta1.StateError, metav1.ConditionFalse)
 	}
-	return nil
+
+	// update the status only if the result of the policy validation changed, not on every health check
+	status := getInstanceStatus(objectInstance)
+	state, changed := applyPolicyCondition(objectInstance, &status)
+	recommended := r.applyRecommendations(ctx, objectInstance, &status)
+	if state == objectInstance.Status.State && !changed {
+		if recommended {
+			// changed recommendations do not change the state, so no Event is emitted for them
+			objectInstance.Status = status
+			return r.ssaStatus(ctx, objectInstance)
+		}
+		return nil
+	}
+
+	objectInstance.Status = status
+	return r.setInstanceStatus(ctx, objectInstance, state, metav1.ConditionTrue)
 }
 
 func (r *RegistryCacheReconciler) handleDeletingState(ctx context.Context, objectInstance *v1beta1.RegistryCache) error {
@@ -137,7 +172,96 @@ func (r *RegistryCacheReconciler) attemptToBecomeReady(ctx context.Context, obje
 		logger.Info("Webhook server not ready!")
 		return nil
 	}
-	return r.setInstanceStatus(ctx, objectInstance, v1beta1.StateReady, metav1.ConditionTrue)
+
+	status := getInstanceStatus(objectInstance)
+	state, _ := applyPolicyCondition(objectInstance, &status)
+	r.applyRecommendations(ctx, objectInstance, &status)
+	objectInstance.Status = status
+
+	return r.setInstanceStatus(ctx, objectInstance, state, metav1.ConditionTrue)
+}
+
+// applyPolicyCondition validates the policy of the instance and records the result in the PolicyValid condition
+// of the status. It returns the state the module should be in, Warning if the policy is invalid, Ready otherwise,
+// and whether the condition changed.
+func applyPolicyCondition(objectInstance *v1beta1.RegistryCache, status *v1beta1.RegistryCacheStatus) (v1beta1.State, bool) {
+	status.Conditions = slices.Clone(status.Conditions)
+
+	policyErrs := policy.Validate(objectInstance.Spec.Policy, field.NewPath("spec").Child("policy"))
+	if len(policyErrs) > 0 {
+		return v1beta1.StateWarning, status.WithPolicyCondition(policyErrs.ToAggregate(), objectInstance.GetGeneration())
+	}
+
+	return v1beta1.StateReady, status.WithPolicyCondition(nil, objectInstance.GetGeneration())
+}
+
+// applyRecommendations inventories the images of the Pods and records the upstreams worth caching in the status.
+// The inventory is repeated at most every recommendationInterval. It returns true if the recommended upstreams changed.
+// The recommendations are optional, so a failed inventory is logged and retried with the next reconciliation
+// instead of keeping the module from becoming ready.
+func (r *RegistryCacheReconciler) applyRecommendations(ctx context.Context, objectInstance *v1beta1.RegistryCache, status *v1beta1.RegistryCacheStatus) bool {
+	now := time.Now()
+	if status.Recommendations != nil && now.Sub(r.lastInventory) < recommendationInterval {
+		return false
+	}
+
+	inventory, err := r.inventoryPods(ctx)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed to inventory the images for the upstream recommendations")
+		return false
+	}
+
+	var configs v1beta1.RegistryCacheConfigList
+	if err := r.List(ctx, &configs); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list the registry cache configs for the upstream recommendations")
+		return false
+	}
+
+	cached := map[string]bool{}
+	for _, config := range configs.Items {
+		cached[upstream.Normalize(config.Spec.Upstream)] = true
+	}
+
+	// an invalid policy is not enforced, so it does not restrict the recommendations either
+	upstreamPolicy := objectInstance.Spec.Policy
+	if len(policy.Validate(upstreamPolicy, field.NewPath("spec").Child("policy"))) > 0 {
+		upstreamPolicy = nil
+	}
+
+	r.lastInventory = now
+
+	upstreams := inventory.Recommend(now, func(upstreamName string) bool {
+		return cached[upstreamName] || !policy.UpstreamAllowed(upstreamPolicy, upstreamName)
+	})
+
+	if status.Recommendations != nil && equality.Semantic.DeepEqual(status.Recommendations.Upstreams, upstreams) {
+		return false
+	}
+
+	status.Recommendations = &v1beta1.UpstreamRecommendations{
+		LastUpdateTime: metav1.NewTime(now),
+		Upstreams:      upstreams,
+	}
+	return true
+}
+
+// inventoryPods lists the Pods of all namespaces in pages and adds every page to the inventory, so that a cluster with
+// many Pods is neither read in one request nor kept in memory at once.
+func (r *RegistryCacheReconciler) inventoryPods(ctx context.Context) (*recommendation.Inventory, error) {
+	inventory := recommendation.NewInventory()
+	continueToken := ""
+	for {
+		var page corev1.PodList
+		if err := r.apiReader.List(ctx, &page, client.Limit(podListPageSize), client.Continue(continueToken)); err != nil {
+			return nil, fmt.Errorf("error while listing pods: %w", err)
+		}
+		inventory.Add(page.Items...)
+
+		continueToken = page.Continue
+		if continueToken == "" {
+			return inventory, nil
+		}
+	}
 }
 
 func getInstanceStatus(objectInstance *v1beta1.RegistryCache) v1beta1.RegistryCacheStatus {
@@ -6,7 +6,9 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -82,9 +84,116 @@ var _ = Describe("RegistryCache controller", func() {
 				return err != nil && apierrors.IsNotFound(err)
 			}, time.Second*60, time.Second*3).Should(BeTrue())
 		})
+
+		It("Should report an invalid policy in the Warning state", func() {
+			const policyResourceName = "invalid-policy"
+			policyNamespacedName := types.NamespacedName{Name: policyResourceName, Namespace: NamespaceName}
+
+			By("By creating a RegistryCache CR with an invalid upstream pattern")
+			registryCacheStub := newRegistryCacheStub(policyResourceName)
+			registryCacheStub.Spec.Policy = &rcapi.RegistryCachePolicy{AllowedUpstreams: []string{"[a-"}}
+			Expect(k8sClient.Create(ctx, registryCacheStub)).To(Succeed())
+
+			By("By waiting for RegistryCache to reach Warning state with the PolicyValid condition set to false")
+			Eventually(func() bool {
+				registryCache := rcapi.RegistryCache{}
+				if err := k8sClient.Get(ctx, policyNamespacedName, &registryCache); err != nil {
+					return false
+				}
+
+				return registryCache.Status.State == rcapi.StateWarning &&
+					meta.IsStatusConditionFalse(registryCache.Status.Conditions, rcapi.ConditionTypePolicyValid)
+			}, time.Second*60, time.Second*3).Should(BeTrue())
+
+			By("By fixing the policy")
+			registryCache := rcapi.RegistryCache{}
+			Expect(k8sClient.Get(ctx, policyNamespacedName, &registryCache)).To(Succeed())
+			registryCache.Spec.Policy.AllowedUpstreams = []string{"*.example.com"}
+			Expect(k8sClient.Update(ctx, &registryCache)).To(Succeed())
+
+			By("By waiting for RegistryCache to reach Ready state")
+			Eventually(func() bool {
+				registryCache := rcapi.RegistryCache{}
+				if err := k8sClient.Get(ctx, policyNamespacedName, &registryCache); err != nil {
+					return false
+				}
+
+				return registryCache.Status.State == rcapi.StateReady &&
+					meta.IsStatusConditionTrue(registryCache.Status.Conditions, rcapi.ConditionTypePolicyValid)
+			}, time.Second*60, time.Second*3).Should(BeTrue())
+
+			Expect(k8sClient.Delete(ctx, &registryCache)).To(Succeed())
+		})
+
+		It("Should recommend the upstreams of the Pod images without a RegistryCacheConfig", func() {
+			const recommendationResourceName = "recommendations"
+			recommendationNamespacedName := types.NamespacedName{Name: recommendationResourceName, Namespace: NamespaceName}
+
+			By("By creating Pods with images from several upstreams")
+			pods := []*corev1.Pod{
+				newPodStub("recommended-1", NamespaceName, "node-1", "registry.example.com/app:v1"),
+				newPodStub("recommended-2", NamespaceName, "node-2", "registry.example.com/app:v1"),
+				newPodStub("cached", NamespaceName, "node-1", "cached.example.com/app:v1"),
+				newPodStub("denied", NamespaceName, "node-1", "denied.example.com/app:v1"),
+			}
+			for _, pod := range pods {
+				Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+			}
+
+			By("By creating a RegistryCacheConfig CR for one of the upstreams")
+			config := newRegistryCacheConfigStub("config-recommendations", NamespaceName, rcapi.RegistryCacheConfigSpec{
+				Upstream: "cached.example.com",
+			})
+			Expect(k8sClient.Create(ctx, config)).To(Succeed())
+			Eventually(func() *metav1.Condition {
+				return getValidatedCondition(ctx, types.NamespacedName{Name: "config-recommendations", Namespace: NamespaceName})
+			}, time.Second*30, time.Millisecond*500).ShouldNot(BeNil())
+
+			By("By creating a RegistryCache CR with a policy denying one of the upstreams")
+			registryCacheStub := newRegistryCacheStub(recommendationResourceName)
+			registryCacheStub.Spec.Policy = &rcapi.RegistryCachePolicy{DeniedUpstreams: []string{"denied.example.com"}}
+			Expect(k8sClient.Create(ctx, registryCacheStub)).To(Succeed())
+
+			By("By waiting for the recommended upstreams")
+			Eventually(func() []rcapi.UpstreamRecommendation {
+				registryCache := rcapi.RegistryCache{}
+				if err := k8sClient.Get(ctx, recommendationNamespacedName, &registryCache); err != nil || registryCache.Status.Recommendations == nil {
+					return nil
+				}
+				return registryCache.Status.Recommendations.Upstreams
+			}, time.Second*60, time.Second*3).Should(ConsistOf(rcapi.UpstreamRecommendation{
+				Upstream:             "registry.example.com",
+				ExampleImage:         "registry.example.com/app:v1",
+				Images:               1,
+				Nodes:                2,
+				EstimatedPulls:       2,
+				EstimatedPullsPerDay: 2,
+			}))
+
+			registryCache := rcapi.RegistryCache{}
+			Expect(k8sClient.Get(ctx, recommendationNamespacedName, &registryCache)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &registryCache)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, config)).To(Succeed())
+			for _, pod := range pods {
+				Expect(k8sClient.Delete(ctx, pod)).To(Succeed())
+			}
+		})
 	})
 })
 
+func newPodStub(name, namespace, node, image string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: corev1.PodSpec{
+			NodeName:   node,
+			Containers: []corev1.Container{{Name: "app", Image: image}},
+		},
+	}
+}
+
 func newRegistryCacheStub(name string) *rcapi.RegistryCache {
 	return &rcapi.RegistryCache{
 		ObjectMeta: metav1.ObjectMeta{
@@ -6,7 +6,9 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
 	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
@@ -16,6 +18,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	rcapi "github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/index"
+	"github.com/kyma-project/registry-cache/internal/prewarm"
+	"github.com/kyma-project/registry-cache/internal/webhook/validations/mocks"
 )
 
 var (
@@ -47,6 +52,9 @@ var _ = BeforeSuite(func() {
 	Expect(err).NotTo(HaveOccurred())
 	Expect(k8sClient).NotTo(BeNil())
 
+	err = clientgoscheme.AddToScheme(k8sClient.Scheme())
+	Expect(err).NotTo(HaveOccurred())
+
 	err = rcapi.AddToScheme(k8sClient.Scheme())
 	Expect(err).NotTo(HaveOccurred())
 
@@ -58,11 +66,36 @@ var _ = BeforeSuite(func() {
 	})
 	Expect(err).ToNot(HaveOccurred())
 
+	err = index.Setup(context.Background(), mgr.GetFieldIndexer())
+	Expect(err).ToNot(HaveOccurred())
+
+	err = index.SetupEvents(context.Background(), mgr.GetFieldIndexer())
+	Expect(err).ToNot(HaveOccurred())
+
 	reconciler := NewRegistryCacheReconciler(mgr, healthz.Ping)
 	Expect(reconciler).NotTo(BeNil())
 	err = reconciler.SetupWithManager(mgr)
 	Expect(err).To(BeNil())
 
+	dnsValidator := &mocks.DNSValidator{}
+	dnsValidator.On("IsResolvable", mock.Anything, mock.Anything).Return(true)
+
+	// The revalidation interval exceeds the timeouts of the tests, so that they observe the reactions to the watches.
+	configReconciler := NewRegistryCacheConfigReconciler(mgr, dnsValidator, DefaultRevalidationInterval, nil)
+	Expect(configReconciler).NotTo(BeNil())
+	err = configReconciler.SetupWithManager(mgr)
+	Expect(err).To(BeNil())
+
+	pullFailureReconciler := NewPullFailureReconciler(mgr, DefaultPullFailureWindow)
+	Expect(pullFailureReconciler).NotTo(BeNil())
+	err = pullFailureReconciler.SetupWithManager(mgr)
+	Expect(err).To(BeNil())
+
+	prewarmReconciler := NewPrewarmReconciler(mgr, prewarm.CranePullerImage)
+	Expect(prewarmReconciler).NotTo(BeNil())
+	err = prewarmReconciler.SetupWithManager(mgr)
+	Expect(err).To(BeNil())
+
 	go func() {
 		defer GinkgoRecover()
 		suiteCtx, cancelFunc = context.WithCancel(context.Background())
@@ -0,0 +1,140 @@
+package credentials
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/kyma-project/registry-cache/internal/distribution"
+	"github.com/kyma-project/registry-cache/internal/upstream"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// LabelDerived marks the Secrets which the module derived from a dockerconfigjson or basic-auth Secret.
+	LabelDerived = "registry-cache.kyma-project.io/derived-credentials"
+
+	usernameKey = "username"
+	passwordKey = "password"
+
+	hashLength = 10
+	// maxNameLength is the maximum length of a Secret name.
+	maxNameLength = 253
+)
+
+// NeedsDerivation returns true if the Secret is not in the canonical format, which is an Opaque Secret
+// with the username and password data entries, but the credentials can be extracted from it.
+func NeedsDerivation(secret *v1.Secret) bool {
+	return secret.Type == v1.SecretTypeDockerConfigJson || secret.Type == v1.SecretTypeBasicAuth
+}
+
+// Extract returns the credentials of the Secret for the upstream. For dockerconfigjson Secrets the entry whose
+// registry matches the upstream is used, Docker Hub aliases and URLs such as `https://index.docker.io/v1/` included.
+func Extract(secret *v1.Secret, upstreamName string) (distribution.Credentials, error) {
+	if secret.Type != v1.SecretTypeDockerConfigJson {
+		// the basic-auth Secret type uses the same data keys as the canonical format
+		return distribution.Credentials{
+			Username: string(secret.Data[usernameKey]),
+			Password: string(secret.Data[passwordKey]),
+		}, nil
+	}
+
+	raw, ok := secret.Data[v1.DockerConfigJsonKey]
+	if !ok {
+		return distribution.Credentials{}, fmt.Errorf("missing %q data entry", v1.DockerConfigJsonKey)
+	}
+
+	var config dockerConfigJSON
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return distribution.Credentials{}, fmt.Errorf("failed to parse %q data entry: %w", v1.DockerConfigJsonKey, err)
+	}
+
+	// several keys may denote the upstream, for example, `docker.io` and `https://index.docker.io/v1/`,
+	// the keys are sorted so that always the same entry is used
+	normalized := upstream.Normalize(upstreamName)
+	for _, registry := range slices.Sorted(maps.Keys(config.Auths)) {
+		if registryUpstream(registry) == normalized {
+			return config.Auths[registry].credentials()
+		}
+	}
+
+	return distribution.Credentials{}, fmt.Errorf("no credentials for upstream %s", upstreamName)
+}
+
+// DerivedSecretName returns the name of the Secret derived for the RegistryCacheConfig. The hash of the credentials
+// is part of the name, so that changed credentials result in a new immutable Secret.
+func DerivedSecretName(configName string, creds distribution.Credentials) string {
+	sum := sha256.Sum256([]byte(creds.Username + "\x00" + creds.Password))
+	suffix := "-credentials-" + hex.EncodeToString(sum[:])[:hashLength]
+
+	if len(configName)+len(suffix) > maxNameLength {
+		configName = strings.TrimRight(configName[:maxNameLength-len(suffix)], "-.")
+	}
+
+	return configName + suffix
+}
+
+// DerivedSecret returns the immutable Secret in the canonical format with the credentials, in the namespace of the RegistryCacheConfig.
+func DerivedSecret(configName, namespace string, creds distribution.Credentials) *v1.Secret {
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DerivedSecretName(configName, creds),
+			Namespace: namespace,
+			Labels:    map[string]string{LabelDerived: "true"},
+		},
+		Type:      v1.SecretTypeOpaque,
+		Immutable: ptr.To(true),
+		Data: map[string][]byte{
+			usernameKey: []byte(creds.Username),
+			passwordKey: []byte(creds.Password),
+		},
+	}
+}
+
+type dockerConfigJSON struct {
+	Auths map[string]dockerConfigEntry `json:"auths"`
+}
+
+type dockerConfigEntry struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Auth     string `json:"auth,omitempty"`
+}
+
+// credentials prefers the base64 encoded `user:password` in auth over the separate username and password, as the Docker CLI does.
+func (e dockerConfigEntry) credentials() (distribution.Credentials, error) {
+	if e.Auth == "" {
+		return distribution.Credentials{Username: e.Username, Password: e.Password}, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(e.Auth)
+	if err != nil {
+		return distribution.Credentials{}, fmt.Errorf("failed to decode auth: %w", err)
+	}
+
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return distribution.Credentials{}, fmt.Errorf("auth must have the format <username>:<password>")
+	}
+
+	return distribution.Credentials{Username: username, Password: password}, nil
+}
+
+// registryUpstream returns the normalized upstream of a key of the auths map, which is either a host or a URL.
+func registryUpstream(registry string) string {
+	if strings.Contains(registry, "://") {
+		if normalized, err := upstream.FromURL(registry); err == nil {
+			return normalized
+		}
+	}
+
+	host, _, _ := strings.Cut(registry, "/")
+	return upstream.Normalize(host)
+}
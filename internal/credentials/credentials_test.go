@@ -0,0 +1,125 @@
+package credentials
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/kyma-project/registry-cache/internal/distribution"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExtract(t *testing.T) {
+	dockerConfig := buildSecret(v1.SecretTypeDockerConfigJson, map[string][]byte{
+		v1.DockerConfigJsonKey: []byte(`{"auths": {
+			"https://index.docker.io/v1/": {"auth": "` + base64.StdEncoding.EncodeToString([]byte("hub-user:hub:password")) + `"},
+			"quay.io": {"username": "quay-user", "password": "quay-password"},
+			"registry.example.com:5000/path": {"username": "example-user", "password": "example-password"}
+		}}`),
+	})
+
+	tests := []struct {
+		name     string
+		secret   v1.Secret
+		upstream string
+		expected distribution.Credentials
+		err      string
+	}{
+		{
+			name:     "dockerconfigjson entry with auth",
+			secret:   dockerConfig,
+			upstream: "docker.io",
+			expected: distribution.Credentials{Username: "hub-user", Password: "hub:password"},
+		},
+		{
+			name:     "dockerconfigjson entry with username and password",
+			secret:   dockerConfig,
+			upstream: "Quay.io:443",
+			expected: distribution.Credentials{Username: "quay-user", Password: "quay-password"},
+		},
+		{
+			name:     "dockerconfigjson entry with port and path",
+			secret:   dockerConfig,
+			upstream: "registry.example.com:5000",
+			expected: distribution.Credentials{Username: "example-user", Password: "example-password"},
+		},
+		{
+			name:     "dockerconfigjson without entry for the upstream",
+			secret:   dockerConfig,
+			upstream: "ghcr.io",
+			err:      "no credentials for upstream ghcr.io",
+		},
+		{
+			name:     "dockerconfigjson with invalid auth",
+			secret:   buildSecret(v1.SecretTypeDockerConfigJson, map[string][]byte{v1.DockerConfigJsonKey: []byte(`{"auths": {"quay.io": {"auth": "bm8tY29sb24="}}}`)}),
+			upstream: "quay.io",
+			err:      "auth must have the format",
+		},
+		{
+			name:     "dockerconfigjson with invalid JSON",
+			secret:   buildSecret(v1.SecretTypeDockerConfigJson, map[string][]byte{v1.DockerConfigJsonKey: []byte(`{`)}),
+			upstream: "quay.io",
+			err:      "failed to parse",
+		},
+		{
+			name:     "basic-auth",
+			secret:   buildSecret(v1.SecretTypeBasicAuth, map[string][]byte{"username": []byte("user"), "password": []byte("password")}),
+			upstream: "quay.io",
+			expected: distribution.Credentials{Username: "user", Password: "password"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creds, err := Extract(&tt.secret, tt.upstream)
+
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, creds)
+		})
+	}
+}
+
+func TestNeedsDerivation(t *testing.T) {
+	require.True(t, NeedsDerivation(&v1.Secret{Type: v1.SecretTypeDockerConfigJson}))
+	require.True(t, NeedsDerivation(&v1.Secret{Type: v1.SecretTypeBasicAuth}))
+	require.False(t, NeedsDerivation(&v1.Secret{Type: v1.SecretTypeOpaque}))
+	require.False(t, NeedsDerivation(&v1.Secret{}))
+}
+
+func TestDerivedSecret(t *testing.T) {
+	creds := distribution.Credentials{Username: "user", Password: "password"}
+
+	secret := DerivedSecret("config", "default", creds)
+
+	require.Regexp(t, `^config-credentials-[0-9a-f]{10}$`, secret.Name)
+	require.Equal(t, "default", secret.Namespace)
+	require.Equal(t, v1.SecretTypeOpaque, secret.Type)
+	require.True(t, *secret.Immutable)
+	require.Equal(t, map[string][]byte{"username": []byte("user"), "password": []byte("password")}, secret.Data)
+
+	t.Run("changed credentials result in a new name", func(t *testing.T) {
+		require.Equal(t, secret.Name, DerivedSecretName("config", creds))
+		require.NotEqual(t, secret.Name, DerivedSecretName("config", distribution.Credentials{Username: "user", Password: "changed"}))
+	})
+
+	t.Run("long config name is truncated", func(t *testing.T) {
+		name := DerivedSecretName(strings.Repeat("a", 253), creds)
+
+		require.Len(t, name, 253)
+		require.True(t, strings.HasSuffix(name, secret.Name[len("config"):]))
+	})
+}
+
+func buildSecret(secretType v1.SecretType, data map[string][]byte) v1.Secret {
+	return v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "upstream-secret", Namespace: "default"},
+		Type:       secretType,
+		Data:       data,
+	}
+}
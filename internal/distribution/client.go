@@ -0,0 +1,255 @@
+package distribution
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// DefaultTimeout is the timeout of a single request to the registry.
+const DefaultTimeout = 5 * time.Second
+
+// ErrUnauthorized is returned when the registry rejects the credentials.
+var ErrUnauthorized = errors.New("registry rejected the credentials")
+
+// Credentials are the username and password used to authenticate against the registry.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// Proxy contains the proxies through which the requests to the registry are sent.
+type Proxy struct {
+	// HTTPProxy is the proxy of the requests to registries with an http URL.
+	HTTPProxy string
+	// HTTPSProxy is the proxy of the requests to registries with an https URL.
+	HTTPSProxy string
+}
+
+// ProxyFunc returns the proxy function of an HTTP transport which sends the requests through the proxies.
+func (p *Proxy) ProxyFunc() func(*http.Request) (*url.URL, error) {
+	proxyFunc := (&httpproxy.Config{HTTPProxy: p.HTTPProxy, HTTPSProxy: p.HTTPSProxy}).ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+}
+
+// Client performs requests against the OCI distribution API of a registry.
+type Client struct {
+	httpClient *http.Client
+}
+
+// NewClient constructs a Client. A default HTTP client with DefaultTimeout is used if httpClient is nil.
+func NewClient(httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	return &Client{
+		httpClient: httpClient,
+	}
+}
+
+// Ping performs the API version check on the /v2/ endpoint of the registry. If the registry requests authentication,
+// Ping follows the Basic or Bearer token challenge with the given credentials. It returns ErrUnauthorized
+// if the registry rejects the credentials, or if it requires credentials and none are given.
+// With a proxy, all requests, the one to the token realm included, are sent through the proxy.
+func (c *Client) Ping(ctx context.Context, registryURL string, proxy *Proxy, credentials *Credentials) error {
+	if proxy != nil {
+		proxied := c.withProxy(proxy)
+		defer proxied.httpClient.CloseIdleConnections()
+		c = proxied
+	}
+
+	endpoint := strings.TrimSuffix(registryURL, "/") + "/v2/"
+
+	resp, err := c.get(ctx, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	defer drain(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized:
+	default:
+		return fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, endpoint)
+	}
+
+	if credentials == nil {
+		return ErrUnauthorized
+	}
+
+	scheme, params := parseChallenge(resp.Header.Get("WWW-Authenticate"))
+	switch strings.ToLower(scheme) {
+	case "basic":
+		return c.basicAuth(ctx, endpoint, credentials)
+	case "bearer":
+		return c.bearerAuth(ctx, endpoint, params, credentials)
+	default:
+		return fmt.Errorf("unsupported authentication scheme %q from %s", scheme, endpoint)
+	}
+}
+
+// withProxy returns a copy of the client whose transport sends the requests through the proxy.
+func (c *Client) withProxy(proxy *Proxy) *Client {
+	transport, ok := c.httpClient.Transport.(*http.Transport)
+	if !ok {
+		transport = http.DefaultTransport.(*http.Transport)
+	}
+	transport = transport.Clone()
+	transport.Proxy = proxy.ProxyFunc()
+
+	httpClient := *c.httpClient
+	httpClient.Transport = transport
+
+	return &Client{httpClient: &httpClient}
+}
+
+func (c *Client) basicAuth(ctx context.Context, endpoint string, credentials *Credentials) error {
+	resp, err := c.get(ctx, endpoint, func(req *http.Request) {
+		req.SetBasicAuth(credentials.Username, credentials.Password)
+	})
+	if err != nil {
+		return err
+	}
+	defer drain(resp)
+
+	return checkAuthResponse(resp, endpoint)
+}
+
+// bearerAuth requests a token from the realm of the challenge with the credentials and uses it on the /v2/ endpoint.
+func (c *Client) bearerAuth(ctx context.Context, endpoint string, params map[string]string, credentials *Credentials) error {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return fmt.Errorf("invalid token realm %q from %s", params["realm"], endpoint)
+	}
+
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if value, ok := params[key]; ok {
+			query.Set(key, value)
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	tokenResp, err := c.get(ctx, realm.String(), func(req *http.Request) {
+		req.SetBasicAuth(credentials.Username, credentials.Password)
+	})
+	if err != nil {
+		return err
+	}
+	defer drain(tokenResp)
+
+	if err := checkAuthResponse(tokenResp, realm.String()); err != nil {
+		return err
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(tokenResp.Body).Decode(&token); err != nil {
+		return fmt.Errorf("failed to decode token response from %s: %w", realm.String(), err)
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+
+	resp, err := c.get(ctx, endpoint, func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer "+token.Token)
+	})
+	if err != nil {
+		return err
+	}
+	defer drain(resp)
+
+	return checkAuthResponse(resp, endpoint)
+}
+
+// Probe performs an unauthenticated request on the /v2/ endpoint of the registry and returns the status code.
+// A registry which is up responds with 200, or with 401 if it requires authentication.
+func (c *Client) Probe(ctx context.Context, registryURL string) (int, error) {
+	endpoint := strings.TrimSuffix(registryURL, "/") + "/v2/"
+
+	resp, err := c.get(ctx, endpoint, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer drain(resp)
+
+	return resp.StatusCode, nil
+}
+
+func (c *Client) get(ctx context.Context, endpoint string, decorate func(*http.Request)) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", endpoint, err)
+	}
+	if decorate != nil {
+		decorate(req)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", endpoint, err)
+	}
+
+	return resp, nil
+}
+
+func checkAuthResponse(resp *http.Response, endpoint string) error {
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	default:
+		return fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, endpoint)
+	}
+}
+
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
+
+// parseChallenge parses a WWW-Authenticate header such as `Bearer realm="https://auth.io/token",service="registry.io"`.
+func parseChallenge(header string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	params := map[string]string{}
+
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimLeft(rest, ", ") {
+		key, value, found := strings.Cut(rest, "=")
+		if !found {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				params[key] = value[1:]
+				break
+			}
+			params[key] = value[1 : end+1]
+			rest = value[end+2:]
+			continue
+		}
+
+		value, rest, _ = strings.Cut(value, ",")
+		params[key] = strings.TrimSpace(value)
+	}
+
+	return scheme, params
+}
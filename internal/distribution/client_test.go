@@ -0,0 +1,157 @@
+package distribution
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testUsername = "user"
+	testPassword = "password"
+	testToken    = "test-token"
+)
+
+func TestPing(t *testing.T) {
+	validCredentials := &Credentials{Username: testUsername, Password: testPassword}
+	invalidCredentials := &Credentials{Username: testUsername, Password: "wrong"}
+
+	t.Run("anonymous registry", func(t *testing.T) {
+		registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer registry.Close()
+
+		require.NoError(t, NewClient(nil).Ping(context.Background(), registry.URL, nil, nil))
+		require.NoError(t, NewClient(nil).Ping(context.Background(), registry.URL, nil, validCredentials))
+	})
+
+	t.Run("basic authentication", func(t *testing.T) {
+		registry := newBasicAuthRegistry()
+		defer registry.Close()
+
+		require.NoError(t, NewClient(nil).Ping(context.Background(), registry.URL, nil, validCredentials))
+		require.ErrorIs(t, NewClient(nil).Ping(context.Background(), registry.URL, nil, invalidCredentials), ErrUnauthorized)
+		require.ErrorIs(t, NewClient(nil).Ping(context.Background(), registry.URL, nil, nil), ErrUnauthorized)
+	})
+
+	t.Run("bearer token authentication", func(t *testing.T) {
+		registry := newTokenAuthRegistry(t)
+		defer registry.Close()
+
+		require.NoError(t, NewClient(nil).Ping(context.Background(), registry.URL, nil, validCredentials))
+		require.ErrorIs(t, NewClient(nil).Ping(context.Background(), registry.URL+"/", nil, invalidCredentials), ErrUnauthorized)
+	})
+
+	t.Run("through the proxy", func(t *testing.T) {
+		var requested []string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requested = append(requested, r.URL.String())
+			if username, password, ok := r.BasicAuth(); ok && username == testUsername && password == testPassword {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer proxy.Close()
+
+		require.NoError(t, NewClient(nil).Ping(context.Background(), "http://registry.example.com", &Proxy{HTTPProxy: proxy.URL}, validCredentials))
+		require.Equal(t, []string{"http://registry.example.com/v2/", "http://registry.example.com/v2/"}, requested)
+	})
+
+	t.Run("unexpected status code", func(t *testing.T) {
+		registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer registry.Close()
+
+		err := NewClient(nil).Ping(context.Background(), registry.URL, nil, validCredentials)
+		require.ErrorContains(t, err, "unexpected status code 404")
+		require.NotErrorIs(t, err, ErrUnauthorized)
+	})
+
+	t.Run("unreachable registry", func(t *testing.T) {
+		registry := httptest.NewServer(http.NotFoundHandler())
+		registry.Close()
+
+		err := NewClient(nil).Ping(context.Background(), registry.URL, nil, validCredentials)
+		require.Error(t, err)
+		require.NotErrorIs(t, err, ErrUnauthorized)
+	})
+}
+
+func TestProbe(t *testing.T) {
+	t.Run("status code of the /v2/ endpoint", func(t *testing.T) {
+		registry := newBasicAuthRegistry()
+		defer registry.Close()
+
+		status, err := NewClient(nil).Probe(context.Background(), registry.URL)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, status)
+	})
+
+	t.Run("unreachable registry", func(t *testing.T) {
+		registry := httptest.NewServer(http.NotFoundHandler())
+		registry.Close()
+
+		_, err := NewClient(nil).Probe(context.Background(), registry.URL)
+		require.Error(t, err)
+	})
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/nginx:pull,push"`)
+
+	require.Equal(t, "Bearer", scheme)
+	require.Equal(t, map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+		"scope":   "repository:library/nginx:pull,push",
+	}, params)
+
+	scheme, params = parseChallenge(`Basic realm=registry`)
+
+	require.Equal(t, "Basic", scheme)
+	require.Equal(t, map[string]string{"realm": "registry"}, params)
+}
+
+func newBasicAuthRegistry() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); ok && username == testUsername && password == testPassword {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+}
+
+func newTokenAuthRegistry(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	registry := httptest.NewServer(mux)
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "test-registry", r.URL.Query().Get("service"))
+
+		if username, password, ok := r.BasicAuth(); !ok || username != testUsername || password != testPassword {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"token":%q}`, testToken)
+	})
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer "+testToken {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test-registry"`, registry.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	return registry
+}
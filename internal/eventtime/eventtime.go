@@ -0,0 +1,21 @@
+package eventtime
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// LastSeen returns the time at which the Event occurred the last time, for Events in the core and in the events.k8s.io format.
+func LastSeen(event *corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case event.Series != nil && !event.Series.LastObservedTime.IsZero():
+		return event.Series.LastObservedTime.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
@@ -0,0 +1,26 @@
+package eventtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLastSeen(t *testing.T) {
+	now := time.Date(2025, 3, 4, 9, 15, 0, 0, time.UTC)
+
+	event := corev1.Event{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))}}
+	require.Equal(t, now.Add(-time.Hour), LastSeen(&event))
+
+	event.EventTime = metav1.NewMicroTime(now.Add(-time.Minute * 30))
+	require.Equal(t, now.Add(-time.Minute*30), LastSeen(&event))
+
+	event.Series = &corev1.EventSeries{LastObservedTime: metav1.NewMicroTime(now.Add(-time.Minute * 10))}
+	require.Equal(t, now.Add(-time.Minute*10), LastSeen(&event))
+
+	event.LastTimestamp = metav1.NewTime(now)
+	require.Equal(t, now, LastSeen(&event))
+}
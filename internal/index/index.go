@@ -0,0 +1,81 @@
+package index
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/pullfailure"
+	"github.com/kyma-project/registry-cache/internal/upstream"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// RegistryCacheConfigSecretReferenceName is the field index of RegistryCacheConfig resources by spec.secretReferenceName.
+	RegistryCacheConfigSecretReferenceName = "spec.secretReferenceName"
+	// RegistryCacheConfigUpstream is the field index of RegistryCacheConfig resources by the normalized spec.upstream.
+	// Use upstream.Normalize to build the lookup value.
+	RegistryCacheConfigUpstream = "spec.upstream"
+	// EventImageUpstream is the field index of Pod Events about failed image pulls by the normalized upstream of the image.
+	// Use upstream.Normalize to build the lookup value.
+	EventImageUpstream = "pullFailure.upstream"
+)
+
+// Setup registers the field indexes of RegistryCacheConfig resources used by the controllers and webhooks of the module.
+func Setup(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &v1beta1.RegistryCacheConfig{}, RegistryCacheConfigSecretReferenceName, SecretReferenceName); err != nil {
+		return fmt.Errorf("failed to index %s: %w", RegistryCacheConfigSecretReferenceName, err)
+	}
+
+	if err := indexer.IndexField(ctx, &v1beta1.RegistryCacheConfig{}, RegistryCacheConfigUpstream, Upstream); err != nil {
+		return fmt.Errorf("failed to index %s: %w", RegistryCacheConfigUpstream, err)
+	}
+
+	return nil
+}
+
+// SetupEvents registers the field indexes of Events. Indexing starts the informer of Events, so they are only registered
+// if a controller which uses them is enabled.
+func SetupEvents(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &corev1.Event{}, EventImageUpstream, ImageUpstream); err != nil {
+		return fmt.Errorf("failed to index %s: %w", EventImageUpstream, err)
+	}
+
+	return nil
+}
+
+// SecretReferenceName extracts the value of the RegistryCacheConfigSecretReferenceName index.
+func SecretReferenceName(obj client.Object) []string {
+	config, ok := obj.(*v1beta1.RegistryCacheConfig)
+	if !ok || config.Spec.SecretReferenceName == nil {
+		return nil
+	}
+
+	return []string{*config.Spec.SecretReferenceName}
+}
+
+// Upstream extracts the value of the RegistryCacheConfigUpstream index.
+func Upstream(obj client.Object) []string {
+	config, ok := obj.(*v1beta1.RegistryCacheConfig)
+	if !ok || config.Spec.Upstream == "" {
+		return nil
+	}
+
+	return []string{upstream.Normalize(config.Spec.Upstream)}
+}
+
+// ImageUpstream extracts the value of the EventImageUpstream index. Events which are no failed image pulls are not indexed.
+func ImageUpstream(obj client.Object) []string {
+	event, ok := obj.(*corev1.Event)
+	if !ok {
+		return nil
+	}
+
+	failure, ok := pullfailure.FromEvent(event)
+	if !ok {
+		return nil
+	}
+
+	return []string{failure.Upstream()}
+}
@@ -0,0 +1,85 @@
+package index
+
+import (
+	"testing"
+
+	"github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestSecretReferenceName(t *testing.T) {
+	t.Run("returns the referenced secret name", func(t *testing.T) {
+		config := &v1beta1.RegistryCacheConfig{
+			Spec: v1beta1.RegistryCacheConfigSpec{
+				Upstream:            "ghcr.io",
+				SecretReferenceName: ptr.To("ghcr-credentials"),
+			},
+		}
+
+		require.Equal(t, []string{"ghcr-credentials"}, SecretReferenceName(config))
+	})
+
+	t.Run("returns nothing for a config without secret reference", func(t *testing.T) {
+		config := &v1beta1.RegistryCacheConfig{
+			Spec: v1beta1.RegistryCacheConfigSpec{
+				Upstream: "ghcr.io",
+			},
+		}
+
+		require.Empty(t, SecretReferenceName(config))
+	})
+
+	t.Run("returns nothing for other objects", func(t *testing.T) {
+		require.Empty(t, SecretReferenceName(&corev1.Secret{}))
+	})
+}
+
+func TestUpstream(t *testing.T) {
+	t.Run("returns the normalized upstream", func(t *testing.T) {
+		config := &v1beta1.RegistryCacheConfig{
+			Spec: v1beta1.RegistryCacheConfigSpec{
+				Upstream: "GHCR.io:443",
+			},
+		}
+
+		require.Equal(t, []string{"ghcr.io"}, Upstream(config))
+	})
+
+	t.Run("returns nothing for a config without upstream", func(t *testing.T) {
+		require.Empty(t, Upstream(&v1beta1.RegistryCacheConfig{}))
+	})
+
+	t.Run("returns nothing for other objects", func(t *testing.T) {
+		require.Empty(t, Upstream(&corev1.Secret{}))
+	})
+}
+
+func TestImageUpstream(t *testing.T) {
+	t.Run("returns the upstream of the image which failed to pull", func(t *testing.T) {
+		event := &corev1.Event{
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "app"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "Failed",
+			Message:        `Failed to pull image "GHCR.io:443/org/app:v1": unauthorized`,
+		}
+
+		require.Equal(t, []string{"ghcr.io"}, ImageUpstream(event))
+	})
+
+	t.Run("returns nothing for other events", func(t *testing.T) {
+		event := &corev1.Event{
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "app"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "BackOff",
+			Message:        "Back-off restarting failed container app in pod app",
+		}
+
+		require.Empty(t, ImageUpstream(event))
+	})
+
+	t.Run("returns nothing for other objects", func(t *testing.T) {
+		require.Empty(t, ImageUpstream(&corev1.Secret{}))
+	})
+}
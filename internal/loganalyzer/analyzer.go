@@ -0,0 +1,202 @@
+package loganalyzer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/workload"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DefaultInterval is the interval in which the logs of the registry cache Pods are analyzed.
+// Every analysis covers the logs written since the previous one.
+const DefaultInterval = time.Minute * 5
+
+// containerName is the name of the registry container in the registry cache Pods.
+const containerName = "registry-cache"
+
+// LogReader streams the logs of the registry container of a registry cache Pod.
+type LogReader interface {
+	// Logs returns the log entries written since the given time.
+	Logs(ctx context.Context, pod *corev1.Pod, since time.Time) (io.ReadCloser, error)
+}
+
+// PodLogReader reads the Pod logs through the API server.
+type PodLogReader struct {
+	clientset kubernetes.Interface
+}
+
+// NewPodLogReader constructs a PodLogReader.
+func NewPodLogReader(clientset kubernetes.Interface) *PodLogReader {
+	return &PodLogReader{clientset: clientset}
+}
+
+// Logs implements LogReader.
+func (r *PodLogReader) Logs(ctx context.Context, pod *corev1.Pod, since time.Time) (io.ReadCloser, error) {
+	return r.clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: containerName,
+		SinceTime: &metav1.Time{Time: since},
+	}).Stream(ctx)
+}
+
+// Analyzer periodically classifies the error entries in the logs of the registry cache Pods of every RegistryCacheConfig,
+// and reports the counts in status.logAnalysis and rejected credentials in the CredentialsSuspect condition.
+// It implements the manager.Runnable interface.
+type Analyzer struct {
+	client   client.Client
+	logs     LogReader
+	interval time.Duration
+	now      func() time.Time
+}
+
+// New constructs an Analyzer which analyzes the logs every interval.
+func New(c client.Client, logs LogReader, interval time.Duration) *Analyzer {
+	return &Analyzer{
+		client:   c,
+		logs:     logs,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Start analyzes the logs until the context is cancelled.
+func (a *Analyzer) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, a.AnalyzeAll, a.interval)
+	return nil
+}
+
+// NeedLeaderElection implements the LeaderElectionRunnable interface, so that only the leader updates the status.
+func (*Analyzer) NeedLeaderElection() bool {
+	return true
+}
+
+// AnalyzeAll analyzes the logs of the registry caches of all RegistryCacheConfigs once. Configs without
+// registry cache Pods are skipped.
+func (a *Analyzer) AnalyzeAll(ctx context.Context) {
+	logger := log.FromContext(ctx).WithName("log-analyzer")
+
+	var configs v1beta1.RegistryCacheConfigList
+	if err := a.client.List(ctx, &configs); err != nil {
+		logger.Error(err, "failed to list registry cache configs")
+		return
+	}
+
+	since := a.now().Add(-a.interval)
+	for _, config := range configs.Items {
+		if !config.DeletionTimestamp.IsZero() || config.Spec.Upstream == "" {
+			continue
+		}
+
+		analysis, err := a.analyze(ctx, config.Spec.Upstream, since)
+		if err != nil {
+			logger.Error(err, "failed to analyze the logs of the registry cache", "namespace", config.Namespace, "name", config.Name)
+			continue
+		}
+		if analysis == nil {
+			continue
+		}
+
+		if err := a.updateStatus(ctx, client.ObjectKeyFromObject(&config), analysis); err != nil {
+			logger.Error(err, "failed to update the log analysis", "namespace", config.Namespace, "name", config.Name)
+		}
+	}
+}
+
+// analyze counts the classified error entries of all registry cache Pods of the upstream. It returns nil if there are no Pods.
+func (a *Analyzer) analyze(ctx context.Context, upstream string, since time.Time) (*v1beta1.LogAnalysis, error) {
+	var pods corev1.PodList
+	if err := a.client.List(ctx, &pods, client.InNamespace(workload.Namespace), client.MatchingLabels(workload.Labels(upstream))); err != nil {
+		return nil, fmt.Errorf("error while listing the pods of the registry cache: %w", err)
+	}
+	if len(pods.Items) == 0 {
+		return nil, nil
+	}
+
+	analysis := &v1beta1.LogAnalysis{Window: metav1.Duration{Duration: a.interval}}
+	for _, pod := range pods.Items {
+		if err := a.analyzePod(ctx, &pod, since, analysis); err != nil {
+			return nil, err
+		}
+	}
+
+	return analysis, nil
+}
+
+func (a *Analyzer) analyzePod(ctx context.Context, pod *corev1.Pod, since time.Time, analysis *v1beta1.LogAnalysis) error {
+	stream, err := a.logs.Logs(ctx, pod, since)
+	if err != nil {
+		return fmt.Errorf("failed to read the logs of pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+	defer func() { _ = stream.Close() }()
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		category, ok := Classify(scanner.Text())
+		if !ok {
+			continue
+		}
+
+		switch category {
+		case CategoryAuthFailure:
+			analysis.AuthFailures++
+		case CategoryRateLimited:
+			analysis.RateLimited++
+		case CategoryUpstreamError:
+			analysis.UpstreamErrors++
+		case CategoryNotFound:
+			analysis.NotFound++
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read the logs of pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+	return nil
+}
+
+// updateStatus sets the log analysis and the CredentialsSuspect condition on the latest version of the config.
+func (a *Analyzer) updateStatus(ctx context.Context, key types.NamespacedName, analysis *v1beta1.LogAnalysis) error {
+	var config v1beta1.RegistryCacheConfig
+	if err := a.client.Get(ctx, key, &config); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	original := config.DeepCopy()
+	config.Status.LogAnalysis = analysis
+
+	if analysis.AuthFailures > 0 {
+		config.CredentialsSuspectUpdateConditionTrue(fmt.Sprintf("the upstream rejected %d requests with authentication errors within %s, %s",
+			analysis.AuthFailures, analysis.Window.Duration, credentialsHint(&config)))
+	} else {
+		config.CredentialsSuspectUpdateConditionFalse(fmt.Sprintf("the upstream rejected no requests with authentication errors within %s", analysis.Window.Duration))
+	}
+
+	if equality.Semantic.DeepEqual(original.Status, config.Status) {
+		return nil
+	}
+
+	if err := a.client.Status().Patch(ctx, &config, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})); err != nil {
+		return fmt.Errorf("error while patching status: %w", err)
+	}
+	return nil
+}
+
+// credentialsHint tells the user where to look for the rejected credentials.
+func credentialsHint(config *v1beta1.RegistryCacheConfig) string {
+	if config.Spec.SecretReferenceName != nil {
+		return fmt.Sprintf("check the credentials in secret %s", *config.Spec.SecretReferenceName)
+	}
+	return "the upstream may require credentials in spec.secretReferenceName"
+}
@@ -0,0 +1,142 @@
+package loganalyzer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/workload"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fixtureLogReader returns the log fixture of the Pod.
+type fixtureLogReader struct {
+	fixtures map[string]string
+	since    time.Time
+}
+
+func (r *fixtureLogReader) Logs(_ context.Context, pod *corev1.Pod, since time.Time) (io.ReadCloser, error) {
+	r.since = since
+	fixture, ok := r.fixtures[pod.Name]
+	if !ok {
+		return nil, errors.New("container not found")
+	}
+	return os.Open(fixture)
+}
+
+func TestAnalyzeAll(t *testing.T) {
+	now := time.Date(2025, 3, 4, 9, 15, 0, 0, time.UTC)
+
+	t.Run("counts the error entries and reports rejected credentials", func(t *testing.T) {
+		config := buildConfig("docker.io", ptr.To("docker-credentials"))
+		logs := &fixtureLogReader{fixtures: map[string]string{"registry-docker-io-0": "testdata/registry-cache.log"}}
+		c := fixFakeClient(t, config, buildPod("docker.io"))
+
+		analyzer := New(c, logs, DefaultInterval)
+		analyzer.now = func() time.Time { return now }
+		analyzer.AnalyzeAll(context.Background())
+
+		result := getConfig(t, c)
+		require.Equal(t, &v1beta1.LogAnalysis{
+			Window:         metav1.Duration{Duration: DefaultInterval},
+			AuthFailures:   2,
+			RateLimited:    1,
+			UpstreamErrors: 1,
+			NotFound:       2,
+		}, result.Status.LogAnalysis)
+		require.Equal(t, now.Add(-DefaultInterval), logs.since)
+
+		condition := meta.FindStatusCondition(result.Status.Conditions, string(v1beta1.ConditionTypeCredentialsSuspect))
+		require.NotNil(t, condition)
+		require.Equal(t, metav1.ConditionTrue, condition.Status)
+		require.Equal(t, string(v1beta1.ConditionReasonAuthenticationFailures), condition.Reason)
+		require.Equal(t, "the upstream rejected 2 requests with authentication errors within 5m0s, check the credentials in secret docker-credentials", condition.Message)
+	})
+
+	t.Run("no rejected credentials", func(t *testing.T) {
+		config := buildConfig("docker.io", nil)
+		logs := &fixtureLogReader{fixtures: map[string]string{"registry-docker-io-0": os.DevNull}}
+		c := fixFakeClient(t, config, buildPod("docker.io"))
+
+		New(c, logs, DefaultInterval).AnalyzeAll(context.Background())
+
+		result := getConfig(t, c)
+		require.Equal(t, &v1beta1.LogAnalysis{Window: metav1.Duration{Duration: DefaultInterval}}, result.Status.LogAnalysis)
+
+		condition := meta.FindStatusCondition(result.Status.Conditions, string(v1beta1.ConditionTypeCredentialsSuspect))
+		require.NotNil(t, condition)
+		require.Equal(t, metav1.ConditionFalse, condition.Status)
+		require.Equal(t, string(v1beta1.ConditionReasonNoAuthenticationFailures), condition.Reason)
+	})
+
+	t.Run("config without registry cache pods is skipped", func(t *testing.T) {
+		config := buildConfig("quay.io", nil)
+		c := fixFakeClient(t, config, buildPod("docker.io"))
+
+		New(c, &fixtureLogReader{}, DefaultInterval).AnalyzeAll(context.Background())
+
+		result := getConfig(t, c)
+		require.Nil(t, result.Status.LogAnalysis)
+		require.Empty(t, result.Status.Conditions)
+	})
+
+	t.Run("unreadable logs leave the status unchanged", func(t *testing.T) {
+		config := buildConfig("docker.io", nil)
+		c := fixFakeClient(t, config, buildPod("docker.io"))
+
+		New(c, &fixtureLogReader{}, DefaultInterval).AnalyzeAll(context.Background())
+
+		result := getConfig(t, c)
+		require.Nil(t, result.Status.LogAnalysis)
+	})
+}
+
+func buildConfig(upstream string, secretReferenceName *string) *v1beta1.RegistryCacheConfig {
+	return &v1beta1.RegistryCacheConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "default"},
+		Spec: v1beta1.RegistryCacheConfigSpec{
+			Upstream:            upstream,
+			SecretReferenceName: secretReferenceName,
+		},
+	}
+}
+
+func buildPod(upstream string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      workload.StatefulSetName(upstream) + "-0",
+			Namespace: workload.Namespace,
+			Labels:    workload.Labels(upstream),
+		},
+	}
+}
+
+func fixFakeClient(t *testing.T, objects ...client.Object) client.Client {
+	s := runtime.NewScheme()
+	require.NoError(t, scheme.AddToScheme(s))
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	return fake.NewClientBuilder().
+		WithScheme(s).
+		WithObjects(objects...).
+		WithStatusSubresource(&v1beta1.RegistryCacheConfig{}).
+		Build()
+}
+
+func getConfig(t *testing.T, c client.Client) v1beta1.RegistryCacheConfig {
+	var config v1beta1.RegistryCacheConfig
+	require.NoError(t, c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "config"}, &config))
+	return config
+}
@@ -0,0 +1,132 @@
+package loganalyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Category is the cause of an error entry in the logs of the registry cache.
+type Category string
+
+const (
+	// CategoryAuthFailure is an error caused by credentials which the upstream rejected.
+	CategoryAuthFailure Category = "AuthFailure"
+	// CategoryRateLimited is an error caused by the rate limit of the upstream.
+	CategoryRateLimited Category = "RateLimited"
+	// CategoryUpstreamError is an error caused by a server error of the upstream.
+	CategoryUpstreamError Category = "UpstreamError"
+	// CategoryNotFound is a request for a repository, manifest, or blob which does not exist.
+	CategoryNotFound Category = "NotFound"
+)
+
+var (
+	rateLimitPattern = regexp.MustCompile(`(?i)toomanyrequests|too many requests|rate limit|\b429\b`)
+	authPattern      = regexp.MustCompile(`(?i)unauthorized|\bdenied\b|authentication required|\b401\b|\b403\b|forbidden`)
+	serverPattern    = regexp.MustCompile(`(?i)\b5\d\d (internal server error|not implemented|bad gateway|service unavailable|gateway timeout)`)
+	notFoundCodes    = []string{"manifest unknown", "blob unknown", "name unknown"}
+)
+
+// Classify returns the category of an error entry written by the distribution registry in the logfmt or the JSON format.
+// Entries which are no errors, or errors of another cause, are not classified.
+func Classify(line string) (Category, bool) {
+	fields := parseEntry(line)
+	if fields["level"] != "error" {
+		return "", false
+	}
+
+	text := strings.Join([]string{fields["msg"], fields["err.code"], fields["err.message"], fields["err.detail"]}, " ")
+	status := fields["http.response.status"]
+
+	switch {
+	case rateLimitPattern.MatchString(text) || status == "429":
+		return CategoryRateLimited, true
+	case authPattern.MatchString(text) || status == "401" || status == "403":
+		return CategoryAuthFailure, true
+	case serverPattern.MatchString(text) || strings.HasPrefix(status, "5"):
+		return CategoryUpstreamError, true
+	case isNotFound(fields["err.code"]) || status == "404":
+		return CategoryNotFound, true
+	}
+
+	return "", false
+}
+
+func isNotFound(code string) bool {
+	for _, notFoundCode := range notFoundCodes {
+		if strings.EqualFold(code, notFoundCode) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseEntry returns the fields of a log entry. Entries in the JSON format start with a brace, all other entries are parsed as logfmt.
+func parseEntry(line string) map[string]string {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "{") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil
+		}
+
+		fields := make(map[string]string, len(entry))
+		for key, value := range entry {
+			fields[key] = fmt.Sprint(value)
+		}
+		return fields
+	}
+
+	return parseLogfmt(line)
+}
+
+// parseLogfmt parses key=value pairs separated by spaces. Values may be double-quoted with backslash escapes.
+func parseLogfmt(line string) map[string]string {
+	fields := map[string]string{}
+	for i := 0; i < len(line); {
+		for i < len(line) && line[i] == ' ' {
+			i++
+		}
+
+		keyStart := i
+		for i < len(line) && line[i] != '=' && line[i] != ' ' {
+			i++
+		}
+		key := line[keyStart:i]
+		if i >= len(line) || line[i] != '=' {
+			continue
+		}
+		i++
+
+		var value strings.Builder
+		if i < len(line) && line[i] == '"' {
+			for i++; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i+1 < len(line) {
+					i++
+					switch line[i] {
+					case 'n':
+						value.WriteByte('\n')
+					case 't':
+						value.WriteByte('\t')
+					default:
+						value.WriteByte(line[i])
+					}
+					continue
+				}
+				value.WriteByte(line[i])
+			}
+			i++
+		} else {
+			for ; i < len(line) && line[i] != ' '; i++ {
+				value.WriteByte(line[i])
+			}
+		}
+
+		if key != "" {
+			fields[key] = value.String()
+		}
+	}
+
+	return fields
+}
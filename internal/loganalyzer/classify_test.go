@@ -0,0 +1,119 @@
+package loganalyzer
+
+import (
+	"bufio"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected Category
+		ok       bool
+	}{
+		{
+			name: "successful request",
+			line: `time="2025-03-04T09:12:01Z" level=info msg="response completed" http.response.status=200`,
+		},
+		{
+			name: "warning about the missing authorization of the client",
+			line: `time="2025-03-04T09:12:05Z" level=warning msg="error authorizing context: authorization token required"`,
+		},
+		{
+			name:     "unknown manifest",
+			line:     `level=error msg="response completed with error" err.code="manifest unknown" err.message="manifest unknown" http.response.status=404`,
+			expected: CategoryNotFound,
+			ok:       true,
+		},
+		{
+			name:     "unauthorized upstream",
+			line:     `level=error msg="response completed with error" err.code=unknown err.detail="unexpected status code 401 Unauthorized" http.response.status=500`,
+			expected: CategoryAuthFailure,
+			ok:       true,
+		},
+		{
+			name:     "escaped multi-line detail",
+			line:     `level=error msg="response completed with error" err.detail="errors:\ndenied: requested access to the resource is denied\n" http.response.status=500`,
+			expected: CategoryAuthFailure,
+			ok:       true,
+		},
+		{
+			name:     "rate limited upstream",
+			line:     `level=error msg="response completed with error" err.detail="unexpected status code 429 Too Many Requests" http.response.status=500`,
+			expected: CategoryRateLimited,
+			ok:       true,
+		},
+		{
+			name:     "upstream server error",
+			line:     `level=error msg="response completed with error" err.detail="unexpected status code 502 Bad Gateway" http.response.status=500`,
+			expected: CategoryUpstreamError,
+			ok:       true,
+		},
+		{
+			name:     "JSON entry",
+			line:     `{"level":"error","msg":"response completed with error","err.code":"blob unknown","http.response.status":404}`,
+			expected: CategoryNotFound,
+			ok:       true,
+		},
+		{
+			name: "line which is no log entry",
+			line: `panic: runtime error: invalid memory address or nil pointer dereference`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			category, ok := Classify(tt.line)
+
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.expected, category)
+		})
+	}
+}
+
+func TestClassifyFixtures(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		expected map[Category]int
+	}{
+		{
+			fixture: "testdata/registry-cache.log",
+			expected: map[Category]int{
+				CategoryAuthFailure:   2,
+				CategoryRateLimited:   1,
+				CategoryUpstreamError: 1,
+				CategoryNotFound:      2,
+			},
+		},
+		{
+			fixture: "testdata/registry-cache-json.log",
+			expected: map[Category]int{
+				CategoryAuthFailure: 1,
+				CategoryNotFound:    1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			file, err := os.Open(tt.fixture)
+			require.NoError(t, err)
+			defer func() { _ = file.Close() }()
+
+			counts := map[Category]int{}
+			scanner := bufio.NewScanner(file)
+			for scanner.Scan() {
+				if category, ok := Classify(scanner.Text()); ok {
+					counts[category]++
+				}
+			}
+			require.NoError(t, scanner.Err())
+
+			require.Equal(t, tt.expected, counts)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package policy
+
+import (
+	"cmp"
+	"context"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/upstream"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Validate returns the errors of the patterns and the namespace selector of the policy.
+func Validate(policy *v1beta1.RegistryCachePolicy, fldPath *field.Path) field.ErrorList {
+	if policy == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validatePatterns(policy.AllowedUpstreams, fldPath.Child("allowedUpstreams"))...)
+	allErrs = append(allErrs, validatePatterns(policy.DeniedUpstreams, fldPath.Child("deniedUpstreams"))...)
+
+	if policy.NamespaceSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(policy.NamespaceSelector); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("namespaceSelector"), policy.NamespaceSelector, err.Error()))
+		}
+	}
+
+	return append(allErrs, validateStorage(policy.Storage, fldPath.Child("storage"))...)
+}
+
+func validateStorage(storage *v1beta1.StoragePolicy, fldPath *field.Path) field.ErrorList {
+	if storage == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	for _, limit := range []struct {
+		name     string
+		quantity *resource.Quantity
+	}{
+		{name: "minVolumeSize", quantity: storage.MinVolumeSize},
+		{name: "maxVolumeSize", quantity: storage.MaxVolumeSize},
+		{name: "budget", quantity: storage.Budget},
+	} {
+		if limit.quantity != nil && limit.quantity.Sign() <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(limit.name), limit.quantity.String(), "must be greater than 0"))
+		}
+	}
+
+	if storage.MinVolumeSize != nil && storage.MaxVolumeSize != nil && storage.MinVolumeSize.Cmp(*storage.MaxVolumeSize) > 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minVolumeSize"), storage.MinVolumeSize.String(), "must not be greater than maxVolumeSize"))
+	}
+
+	if storage.MinVolumeSize != nil && storage.Budget != nil && storage.MinVolumeSize.Cmp(*storage.Budget) > 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minVolumeSize"), storage.MinVolumeSize.String(), "must not be greater than budget"))
+	}
+
+	return allErrs
+}
+
+func validatePatterns(patterns []string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), pattern, err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
+// Get returns the policy of the RegistryCache which governs the cluster, or nil if there is none or it is invalid.
+// If there are several RegistryCache resources, the oldest one which is not being deleted is used.
+func Get(ctx context.Context, c client.Reader) (*v1beta1.RegistryCachePolicy, error) {
+	var registryCaches v1beta1.RegistryCacheList
+	if err := c.List(ctx, &registryCaches); err != nil {
+		return nil, errors.Wrap(err, "failed to list registry caches")
+	}
+
+	active := slices.DeleteFunc(registryCaches.Items, func(rc v1beta1.RegistryCache) bool {
+		return !rc.DeletionTimestamp.IsZero()
+	})
+	if len(active) == 0 {
+		return nil, nil
+	}
+
+	oldest := slices.MinFunc(active, func(a, b v1beta1.RegistryCache) int {
+		if c := a.CreationTimestamp.Compare(b.CreationTimestamp.Time); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Namespace+"/"+a.Name, b.Namespace+"/"+b.Name)
+	})
+
+	policy := oldest.Spec.Policy
+	if len(Validate(policy, field.NewPath("spec").Child("policy"))) > 0 {
+		return nil, nil
+	}
+
+	return policy, nil
+}
+
+// UpstreamAllowed returns true if the upstream matches none of the denied and, if set, one of the allowed patterns.
+func UpstreamAllowed(policy *v1beta1.RegistryCachePolicy, upstreamName string) bool {
+	if policy == nil {
+		return true
+	}
+
+	normalized := upstream.Normalize(upstreamName)
+	if matchesAny(policy.DeniedUpstreams, normalized) {
+		return false
+	}
+
+	return len(policy.AllowedUpstreams) == 0 || matchesAny(policy.AllowedUpstreams, normalized)
+}
+
+// NamespaceAllowed returns true if the labels of the namespace match the namespace selector of the policy.
+func NamespaceAllowed(policy *v1beta1.RegistryCachePolicy, namespaceLabels map[string]string) bool {
+	if policy == nil || policy.NamespaceSelector == nil {
+		return true
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(policy.NamespaceSelector)
+	if err != nil {
+		return true
+	}
+
+	return selector.Matches(labels.Set(namespaceLabels))
+}
+
+func matchesAny(patterns []string, normalizedUpstream string) bool {
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		matched, _ := path.Match(strings.ToLower(pattern), normalizedUpstream)
+		return matched
+	})
+}
@@ -0,0 +1,172 @@
+package policy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestValidate(t *testing.T) {
+	fldPath := field.NewPath("spec").Child("policy")
+
+	t.Run("valid policy", func(t *testing.T) {
+		require.Empty(t, Validate(&v1beta1.RegistryCachePolicy{
+			AllowedUpstreams: []string{"docker.io", "*.example.com"},
+			DeniedUpstreams:  []string{"registry-[0-9].example.com"},
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"registry-cache": "enabled"},
+			},
+			MaxConfigs: ptr.To[int32](5),
+		}, fldPath))
+	})
+
+	t.Run("no policy", func(t *testing.T) {
+		require.Empty(t, Validate(nil, fldPath))
+	})
+
+	t.Run("invalid patterns and selector", func(t *testing.T) {
+		errs := Validate(&v1beta1.RegistryCachePolicy{
+			AllowedUpstreams: []string{"docker.io", "[a-"},
+			DeniedUpstreams:  []string{"\\"},
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: "Near"}},
+			},
+		}, fldPath)
+
+		require.Len(t, errs, 3)
+		require.Equal(t, "spec.policy.allowedUpstreams[1]", errs[0].Field)
+		require.Equal(t, "spec.policy.deniedUpstreams[0]", errs[1].Field)
+		require.Equal(t, "spec.policy.namespaceSelector", errs[2].Field)
+	})
+
+	t.Run("invalid storage limits", func(t *testing.T) {
+		errs := Validate(&v1beta1.RegistryCachePolicy{
+			Storage: &v1beta1.StoragePolicy{
+				MinVolumeSize: ptr.To(resource.MustParse("200Gi")),
+				MaxVolumeSize: ptr.To(resource.MustParse("100Gi")),
+				Budget:        ptr.To(resource.MustParse("0")),
+			},
+		}, fldPath)
+
+		require.Len(t, errs, 3)
+		require.Equal(t, "spec.policy.storage.budget", errs[0].Field)
+		require.Equal(t, "spec.policy.storage.minVolumeSize", errs[1].Field)
+		require.Contains(t, errs[1].Detail, "maxVolumeSize")
+		require.Equal(t, "spec.policy.storage.minVolumeSize", errs[2].Field)
+		require.Contains(t, errs[2].Detail, "budget")
+	})
+}
+
+func TestUpstreamAllowed(t *testing.T) {
+	policy := &v1beta1.RegistryCachePolicy{
+		AllowedUpstreams: []string{"docker.io", "*.example.com", "registry.internal:*"},
+		DeniedUpstreams:  []string{"forbidden.example.com"},
+	}
+
+	tests := []struct {
+		upstream string
+		allowed  bool
+	}{
+		{upstream: "docker.io", allowed: true},
+		{upstream: "registry-1.docker.io", allowed: true},
+		{upstream: "quay.example.com", allowed: true},
+		{upstream: "Quay.Example.com:443", allowed: true},
+		{upstream: "registry.internal:5000", allowed: true},
+		{upstream: "forbidden.example.com", allowed: false},
+		{upstream: "quay.io", allowed: false},
+		{upstream: "quay.example.com:5000", allowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.upstream, func(t *testing.T) {
+			require.Equal(t, tt.allowed, UpstreamAllowed(policy, tt.upstream))
+		})
+	}
+
+	t.Run("all upstreams are allowed without allow patterns", func(t *testing.T) {
+		require.True(t, UpstreamAllowed(&v1beta1.RegistryCachePolicy{}, "quay.io"))
+		require.True(t, UpstreamAllowed(nil, "quay.io"))
+	})
+}
+
+func TestNamespaceAllowed(t *testing.T) {
+	policy := &v1beta1.RegistryCachePolicy{
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"registry-cache": "enabled"},
+		},
+	}
+
+	require.True(t, NamespaceAllowed(policy, map[string]string{"registry-cache": "enabled", "team": "a"}))
+	require.False(t, NamespaceAllowed(policy, map[string]string{"team": "a"}))
+	require.True(t, NamespaceAllowed(&v1beta1.RegistryCachePolicy{}, nil))
+}
+
+func TestGet(t *testing.T) {
+	now := time.Now()
+
+	t.Run("no registry cache", func(t *testing.T) {
+		policy, err := Get(context.Background(), fixFakeClient())
+
+		require.NoError(t, err)
+		require.Nil(t, policy)
+	})
+
+	t.Run("policy of the oldest registry cache", func(t *testing.T) {
+		older := buildRegistryCache("older", now.Add(-time.Hour), &v1beta1.RegistryCachePolicy{MaxConfigs: ptr.To[int32](1)})
+		newer := buildRegistryCache("newer", now, &v1beta1.RegistryCachePolicy{MaxConfigs: ptr.To[int32](2)})
+
+		policy, err := Get(context.Background(), fixFakeClient(&newer, &older))
+
+		require.NoError(t, err)
+		require.Equal(t, ptr.To[int32](1), policy.MaxConfigs)
+	})
+
+	t.Run("registry cache being deleted is ignored", func(t *testing.T) {
+		deleting := buildRegistryCache("deleting", now.Add(-time.Hour), &v1beta1.RegistryCachePolicy{MaxConfigs: ptr.To[int32](1)})
+		deleting.DeletionTimestamp = &metav1.Time{Time: now}
+		deleting.Finalizers = []string{"registry-cache.kyma-project.io/finalizer"}
+		active := buildRegistryCache("active", now, &v1beta1.RegistryCachePolicy{MaxConfigs: ptr.To[int32](2)})
+
+		policy, err := Get(context.Background(), fixFakeClient(&deleting, &active))
+
+		require.NoError(t, err)
+		require.Equal(t, ptr.To[int32](2), policy.MaxConfigs)
+	})
+
+	t.Run("invalid policy is not enforced", func(t *testing.T) {
+		invalid := buildRegistryCache("invalid", now, &v1beta1.RegistryCachePolicy{AllowedUpstreams: []string{"[a-"}})
+
+		policy, err := Get(context.Background(), fixFakeClient(&invalid))
+
+		require.NoError(t, err)
+		require.Nil(t, policy)
+	})
+}
+
+func buildRegistryCache(name string, created time.Time, policy *v1beta1.RegistryCachePolicy) v1beta1.RegistryCache {
+	return v1beta1.RegistryCache{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "kyma-system",
+			CreationTimestamp: metav1.Time{Time: created},
+		},
+		Spec: v1beta1.RegistryCacheSpec{Policy: policy},
+	}
+}
+
+func fixFakeClient(initObjs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	_ = v1beta1.AddToScheme(scheme)
+
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjs...).Build()
+}
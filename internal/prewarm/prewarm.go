@@ -0,0 +1,192 @@
+package prewarm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gardener/gardener-extension-registry-cache/pkg/constants"
+	"github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/upstream"
+	"github.com/kyma-project/registry-cache/internal/workload"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// CranePullerImage is an image of the crane CLI which can be set with --prewarm-image to enable the pre-warming.
+	CranePullerImage = "gcr.io/go-containerregistry/crane:v0.20.2"
+
+	// MaxImages is the maximum number of images pulled in a round.
+	MaxImages = 50
+
+	// LabelConfigName is the label of the Jobs with the name of the RegistryCacheConfig which they pre-warm.
+	LabelConfigName = "registry-cache.kyma-project.io/prewarm"
+	// AnnotationImage is the annotation of the Jobs with the image which they pull.
+	AnnotationImage = "registry-cache.kyma-project.io/prewarm-image"
+
+	// maxNameLength is the maximum length of a Job name, which the Job controller copies into a label of its Pods.
+	maxNameLength = 63
+
+	// backoffLimit is the number of retries of a failed pull.
+	backoffLimit = 2
+	// activeDeadline is the time after which a pull is failed, including the retries.
+	activeDeadline = time.Minute * 30
+	// ttlAfterFinished is the time after which finished Jobs are deleted.
+	ttlAfterFinished = time.Hour
+)
+
+// Images returns the images to pre-warm: the explicit images first, followed by the images of the workloads which
+// are hosted by the upstream in lexical order. Duplicates are removed and at most MaxImages images are returned.
+func Images(cacheUpstream string, explicit []string, workloads []corev1.PodSpec) []string {
+	normalized := upstream.Normalize(cacheUpstream)
+	seen := map[string]bool{}
+	var images []string
+	add := func(image string) {
+		if image == "" || seen[image] {
+			return
+		}
+		seen[image] = true
+		images = append(images, image)
+	}
+
+	for _, image := range explicit {
+		add(image)
+	}
+
+	var derived []string
+	for _, spec := range workloads {
+		for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+			for _, container := range containers {
+				if container.Image != "" && upstream.FromImage(container.Image) == normalized {
+					derived = append(derived, container.Image)
+				}
+			}
+		}
+	}
+	slices.Sort(derived)
+	for _, image := range derived {
+		add(image)
+	}
+
+	if len(images) > MaxImages {
+		images = images[:MaxImages]
+	}
+
+	return images
+}
+
+// CacheReference returns the reference of the image in the registry cache of the upstream. The registry cache
+// mirrors the repositories of the upstream, so only the registry is replaced. Docker Hub short names are expanded
+// to the library repositories.
+func CacheReference(cacheUpstream, image string) string {
+	repository := image
+	if first, rest, found := strings.Cut(image, "/"); found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		repository = rest
+	}
+	if upstream.Normalize(cacheUpstream) == upstream.DockerHub && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+
+	return fmt.Sprintf("%s.%s.svc:%d/%s", workload.ServiceName(cacheUpstream), workload.Namespace, constants.RegistryCacheServerPort, repository)
+}
+
+// JobName returns the name of the Job which pulls the image in the round started at the given time.
+// The name is unique per image and round, so that a retried reconciliation does not create the Job twice.
+func JobName(configName, image string, round time.Time) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s@%d", image, round.Unix())))
+	suffix := "-prewarm-" + hex.EncodeToString(hash[:])[:10]
+	prefix := strings.TrimRight(configName[:min(len(configName), maxNameLength-len(suffix))], "-.")
+
+	return prefix + suffix
+}
+
+// JobSelector selects the Jobs which pull images through the registry caches, so that only those Jobs need to be cached.
+func JobSelector() labels.Selector {
+	requirement, err := labels.NewRequirement(LabelConfigName, selection.Exists, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	return labels.NewSelector().Add(*requirement)
+}
+
+// NewJob returns the Job in the namespace of the config which pulls the image through the registry cache with the puller image.
+func NewJob(config *v1beta1.RegistryCacheConfig, image, jobName, pullerImage string) *batchv1.Job {
+	labels := map[string]string{LabelConfigName: config.Name}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        jobName,
+			Namespace:   config.Namespace,
+			Labels:      labels,
+			Annotations: map[string]string{AnnotationImage: image},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            ptr.To[int32](backoffLimit),
+			ActiveDeadlineSeconds:   ptr.To(int64(activeDeadline.Seconds())),
+			TTLSecondsAfterFinished: ptr.To(int32(ttlAfterFinished.Seconds())),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy:                corev1.RestartPolicyNever,
+					AutomountServiceAccountToken: ptr.To(false),
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsNonRoot:   ptr.To(true),
+						RunAsUser:      ptr.To[int64](65532),
+						SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+					},
+					Containers: []corev1.Container{{
+						Name:  "pull",
+						Image: pullerImage,
+						// The registry cache serves plain HTTP or TLS with a self-signed certificate, both are accepted with --insecure.
+						// Only the layers are of interest, so the image is written to /dev/null.
+						Args: []string{"pull", "--insecure", CacheReference(config.Spec.Upstream, image), "/dev/null"},
+						SecurityContext: &corev1.SecurityContext{
+							AllowPrivilegeEscalation: ptr.To(false),
+							ReadOnlyRootFilesystem:   ptr.To(true),
+							Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+// Status returns the phase of the pull of the Job, with the reason and the time of its completion if the Job finished.
+func Status(job *batchv1.Job) (v1beta1.PrewarmPhase, string, *metav1.Time) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+
+		switch condition.Type {
+		case batchv1.JobComplete:
+			completionTime := job.Status.CompletionTime
+			if completionTime == nil {
+				completionTime = &condition.LastTransitionTime
+			}
+			return v1beta1.PrewarmPhaseSucceeded, "", completionTime
+		case batchv1.JobFailed:
+			message := condition.Message
+			if message == "" {
+				message = condition.Reason
+			}
+			return v1beta1.PrewarmPhaseFailed, message, &condition.LastTransitionTime
+		}
+	}
+
+	if job.Status.Active > 0 {
+		return v1beta1.PrewarmPhaseRunning, "", nil
+	}
+
+	return v1beta1.PrewarmPhasePending, "", nil
+}
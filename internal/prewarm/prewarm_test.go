@@ -0,0 +1,145 @@
+package prewarm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestImages(t *testing.T) {
+	workloads := []corev1.PodSpec{
+		{
+			InitContainers: []corev1.Container{{Name: "init", Image: "docker.io/library/busybox:1.36"}},
+			Containers:     []corev1.Container{{Name: "web", Image: "nginx:1.27"}, {Name: "sidecar", Image: "quay.io/org/sidecar:v1"}},
+		},
+		{
+			Containers: []corev1.Container{{Name: "web", Image: "nginx:1.27"}, {Name: "cache", Image: "index.docker.io/library/redis:7"}},
+		},
+	}
+
+	images := Images("registry-1.docker.io", []string{"org/app:v1", "nginx:1.27"}, workloads)
+
+	require.Equal(t, []string{
+		"org/app:v1",
+		"nginx:1.27",
+		"docker.io/library/busybox:1.36",
+		"index.docker.io/library/redis:7",
+	}, images)
+}
+
+func TestImagesLimit(t *testing.T) {
+	var explicit []string
+	for i := range MaxImages + 5 {
+		explicit = append(explicit, fmt.Sprintf("quay.io/org/app:v%d", i))
+	}
+
+	images := Images("quay.io", explicit, nil)
+
+	require.Len(t, images, MaxImages)
+	require.Equal(t, "quay.io/org/app:v0", images[0])
+}
+
+func TestCacheReference(t *testing.T) {
+	tests := []struct {
+		upstream string
+		image    string
+		expected string
+	}{
+		{upstream: "docker.io", image: "nginx:1.27", expected: "registry-docker-io.kube-system.svc:5000/library/nginx:1.27"},
+		{upstream: "docker.io", image: "docker.io/nginx", expected: "registry-docker-io.kube-system.svc:5000/library/nginx"},
+		{upstream: "docker.io", image: "org/app:v1", expected: "registry-docker-io.kube-system.svc:5000/org/app:v1"},
+		{upstream: "quay.io", image: "quay.io/org/app@sha256:0123", expected: "registry-quay-io.kube-system.svc:5000/org/app@sha256:0123"},
+		{upstream: "registry.example.com:8443", image: "registry.example.com:8443/team/app/api:v2", expected: "registry-registry-example-com-8443.kube-system.svc:5000/team/app/api:v2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			require.Equal(t, tt.expected, CacheReference(tt.upstream, tt.image))
+		})
+	}
+}
+
+func TestJobName(t *testing.T) {
+	round := time.Date(2025, 6, 2, 3, 0, 0, 0, time.UTC)
+
+	name := JobName("config", "nginx:1.27", round)
+	require.True(t, strings.HasPrefix(name, "config-prewarm-"))
+	require.Equal(t, name, JobName("config", "nginx:1.27", round), "the name must be stable within a round")
+	require.NotEqual(t, name, JobName("config", "nginx:1.27", round.Add(time.Hour)))
+	require.NotEqual(t, name, JobName("config", "redis:7", round))
+
+	long := JobName(strings.Repeat("a", 60)+"-b", "nginx:1.27", round)
+	require.Len(t, long, maxNameLength)
+	require.Empty(t, validation.IsDNS1123Label(long))
+}
+
+func TestNewJob(t *testing.T) {
+	config := &v1beta1.RegistryCacheConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "default"},
+		Spec:       v1beta1.RegistryCacheConfigSpec{Upstream: "quay.io"},
+	}
+
+	job := NewJob(config, "quay.io/org/app:v1", "config-prewarm-0123456789", CranePullerImage)
+
+	require.Equal(t, "default", job.Namespace)
+	require.Equal(t, "config", job.Labels[LabelConfigName])
+	require.Equal(t, "quay.io/org/app:v1", job.Annotations[AnnotationImage])
+	require.Equal(t, corev1.RestartPolicyNever, job.Spec.Template.Spec.RestartPolicy)
+	require.Len(t, job.Spec.Template.Spec.Containers, 1)
+	require.Equal(t, CranePullerImage, job.Spec.Template.Spec.Containers[0].Image)
+	require.Equal(t, []string{"pull", "--insecure", "registry-quay-io.kube-system.svc:5000/org/app:v1", "/dev/null"}, job.Spec.Template.Spec.Containers[0].Args)
+}
+
+func TestStatus(t *testing.T) {
+	completed := metav1.NewTime(time.Date(2025, 6, 2, 3, 1, 0, 0, time.UTC))
+
+	tests := []struct {
+		name           string
+		status         batchv1.JobStatus
+		phase          v1beta1.PrewarmPhase
+		message        string
+		completionTime *metav1.Time
+	}{
+		{name: "created", phase: v1beta1.PrewarmPhasePending},
+		{name: "active", status: batchv1.JobStatus{Active: 1}, phase: v1beta1.PrewarmPhaseRunning},
+		{
+			name: "complete",
+			status: batchv1.JobStatus{
+				CompletionTime: &completed,
+				Conditions:     []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+			},
+			phase:          v1beta1.PrewarmPhaseSucceeded,
+			completionTime: &completed,
+		},
+		{
+			name: "failed",
+			status: batchv1.JobStatus{
+				Conditions: []batchv1.JobCondition{
+					{Type: batchv1.JobFailureTarget, Status: corev1.ConditionFalse},
+					{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded", Message: "Job has reached the specified backoff limit", LastTransitionTime: completed},
+				},
+			},
+			phase:          v1beta1.PrewarmPhaseFailed,
+			message:        "Job has reached the specified backoff limit",
+			completionTime: &completed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			phase, message, completionTime := Status(&batchv1.Job{Status: tt.status})
+
+			require.Equal(t, tt.phase, phase)
+			require.Equal(t, tt.message, message)
+			require.Equal(t, tt.completionTime, completionTime)
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package prober
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	registryutils "github.com/gardener/gardener-extension-registry-cache/pkg/utils/registry"
+	"github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/distribution"
+	"github.com/kyma-project/registry-cache/internal/upstream"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kevents "k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// DefaultInterval is the interval in which the upstream registries are probed.
+	DefaultInterval = time.Minute * 5
+
+	// maxConcurrentProbes bounds the number of upstream registries which are probed at the same time.
+	maxConcurrentProbes = 10
+)
+
+// probeDuration records the latency of the probes. It is kept out of the condition message, so that the status
+// is only patched when the result of the probe changes.
+var probeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "registry_cache_upstream_probe_duration_seconds",
+	Help:    "Duration of the GET /v2/ probes of the upstream registries, by upstream.",
+	Buckets: prometheus.ExponentialBuckets(0.025, 2, 10),
+}, []string{"upstream"})
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(probeDuration)
+}
+
+// Prober periodically requests the /v2/ endpoint of the upstream registry of every RegistryCacheConfig,
+// through the proxy of the config, and reports the result in the UpstreamReachable condition.
+// It implements the manager.Runnable interface.
+type Prober struct {
+	client   client.Client
+	recorder kevents.EventRecorder
+	interval time.Duration
+	timeout  time.Duration
+}
+
+// New constructs a Prober which probes the upstream registries every interval.
+func New(c client.Client, recorder kevents.EventRecorder, interval time.Duration) *Prober {
+	return &Prober{
+		client:   c,
+		recorder: recorder,
+		interval: interval,
+		timeout:  distribution.DefaultTimeout,
+	}
+}
+
+// Start probes the upstream registries until the context is cancelled.
+func (p *Prober) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, p.ProbeAll, p.interval)
+	return nil
+}
+
+// NeedLeaderElection implements the LeaderElectionRunnable interface, so that only the leader updates the conditions.
+func (*Prober) NeedLeaderElection() bool {
+	return true
+}
+
+// ProbeAll probes the upstream registries of all RegistryCacheConfigs once.
+func (p *Prober) ProbeAll(ctx context.Context) {
+	logger := log.FromContext(ctx).WithName("upstream-prober")
+
+	var configs v1beta1.RegistryCacheConfigList
+	if err := p.client.List(ctx, &configs); err != nil {
+		logger.Error(err, "failed to list registry cache configs")
+		return
+	}
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, maxConcurrentProbes)
+	for _, config := range configs.Items {
+		if !config.DeletionTimestamp.IsZero() || config.Spec.Upstream == "" {
+			continue
+		}
+
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			reachable, message := p.probe(ctx, &config)
+			if err := p.updateCondition(ctx, client.ObjectKeyFromObject(&config), reachable, message); err != nil {
+				logger.Error(err, "failed to update the UpstreamReachable condition", "namespace", config.Namespace, "name", config.Name)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// probe requests the /v2/ endpoint of the effective remote URL. The registry is reachable if it responds without a server error,
+// a 401 response of a registry which requires authentication included.
+func (p *Prober) probe(ctx context.Context, config *v1beta1.RegistryCacheConfig) (bool, string) {
+	registryURL := registryutils.GetUpstreamURL(config.Spec.Upstream)
+	if config.Spec.RemoteURL != nil {
+		registryURL = *config.Spec.RemoteURL
+	}
+	endpoint := strings.TrimSuffix(registryURL, "/") + "/v2/"
+
+	// Every probe uses a new transport, as the probes of an upstream are minutes apart, so its connections are closed afterwards.
+	transport := transportFor(config)
+	defer transport.CloseIdleConnections()
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	start := time.Now()
+	status, err := distribution.NewClient(&http.Client{Transport: transport, Timeout: p.timeout}).Probe(ctx, registryURL)
+	probeDuration.WithLabelValues(upstream.Normalize(config.Spec.Upstream)).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		return false, fmt.Sprintf("GET %s failed: %v", endpoint, err)
+	}
+
+	return status < http.StatusInternalServerError, fmt.Sprintf("GET %s returned %d", endpoint, status)
+}
+
+// transportFor returns the HTTP transport with the proxy of the config. Without spec.proxy the proxy of the environment is used.
+func transportFor(config *v1beta1.RegistryCacheConfig) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	proxy := config.Spec.Proxy
+	if proxy == nil || (proxy.HTTPProxy == nil && proxy.HTTPSProxy == nil) {
+		return transport
+	}
+
+	transport.Proxy = (&distribution.Proxy{
+		HTTPProxy:  ptr.Deref(proxy.HTTPProxy, ""),
+		HTTPSProxy: ptr.Deref(proxy.HTTPSProxy, ""),
+	}).ProxyFunc()
+
+	return transport
+}
+
+// updateCondition sets the UpstreamReachable condition on the latest version of the config and emits an Event
+// when the upstream becomes unreachable or reachable again.
+func (p *Prober) updateCondition(ctx context.Context, key types.NamespacedName, reachable bool, message string) error {
+	var config v1beta1.RegistryCacheConfig
+	if err := p.client.Get(ctx, key, &config); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	original := config.DeepCopy()
+	previous := meta.FindStatusCondition(original.Status.Conditions, string(v1beta1.ConditionTypeUpstreamReachable))
+
+	if reachable {
+		config.UpstreamReachableUpdateConditionTrue(message)
+	} else {
+		config.UpstreamReachableUpdateConditionFalse(message)
+	}
+
+	if !equality.Semantic.DeepEqual(original.Status, config.Status) {
+		if err := p.client.Status().Patch(ctx, &config, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})); err != nil {
+			return fmt.Errorf("error while patching status: %w", err)
+		}
+	}
+
+	switch {
+	case !reachable && (previous == nil || previous.Status != metav1.ConditionFalse):
+		p.recorder.Eventf(&config, nil, corev1.EventTypeWarning, string(v1beta1.ConditionReasonUpstreamUnreachable), "Probe", "%s", message)
+	case reachable && previous != nil && previous.Status == metav1.ConditionFalse:
+		p.recorder.Eventf(&config, nil, corev1.EventTypeNormal, string(v1beta1.ConditionReasonUpstreamReachable), "Probe", "%s", message)
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package prober
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	kevents "k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestProbeAll(t *testing.T) {
+	authenticating := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer authenticating.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	tests := []struct {
+		name            string
+		registryURL     string
+		expectedStatus  metav1.ConditionStatus
+		expectedReason  v1beta1.ConditionReason
+		expectedMessage string
+	}{
+		{
+			name:            "registry which requires authentication",
+			registryURL:     authenticating.URL,
+			expectedStatus:  metav1.ConditionTrue,
+			expectedReason:  v1beta1.ConditionReasonUpstreamReachable,
+			expectedMessage: "GET " + authenticating.URL + "/v2/ returned 401",
+		},
+		{
+			name:            "registry with a server error",
+			registryURL:     failing.URL,
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  v1beta1.ConditionReasonUpstreamUnreachable,
+			expectedMessage: "GET " + failing.URL + "/v2/ returned 503",
+		},
+		{
+			name:            "registry which does not accept connections",
+			registryURL:     closed.URL,
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  v1beta1.ConditionReasonUpstreamUnreachable,
+			expectedMessage: "GET " + closed.URL + "/v2/ failed: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := buildConfig(tt.registryURL, nil)
+			c := fixFakeClient(t, config)
+
+			New(c, kevents.NewFakeRecorder(10), DefaultInterval).ProbeAll(context.Background())
+
+			condition := getCondition(t, c)
+			require.Equal(t, tt.expectedStatus, condition.Status)
+			require.Equal(t, string(tt.expectedReason), condition.Reason)
+			require.Contains(t, condition.Message, tt.expectedMessage)
+		})
+	}
+}
+
+func TestProbeAllThroughProxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Host != "registry.test" || r.URL.Path != "/v2/" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	config := buildConfig("http://registry.test", &v1beta1.Proxy{HTTPProxy: ptr.To(proxy.URL)})
+	c := fixFakeClient(t, config)
+
+	New(c, kevents.NewFakeRecorder(10), DefaultInterval).ProbeAll(context.Background())
+
+	condition := getCondition(t, c)
+	require.Equal(t, metav1.ConditionTrue, condition.Status)
+	require.Contains(t, condition.Message, "returned 200")
+}
+
+func TestProbeAllEvents(t *testing.T) {
+	healthy := true
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if !healthy {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer registry.Close()
+
+	config := buildConfig(registry.URL, nil)
+	c := fixFakeClient(t, config)
+	recorder := kevents.NewFakeRecorder(10)
+	p := New(c, recorder, DefaultInterval)
+
+	p.ProbeAll(context.Background())
+	require.Empty(t, recorder.Events, "no event is expected for a reachable upstream")
+
+	healthy = false
+	p.ProbeAll(context.Background())
+	require.Len(t, recorder.Events, 1)
+	require.True(t, strings.HasPrefix(<-recorder.Events, "Warning UpstreamUnreachable GET "+registry.URL+"/v2/ returned 500"))
+
+	p.ProbeAll(context.Background())
+	require.Empty(t, recorder.Events, "no event is expected while the upstream stays unreachable")
+
+	healthy = true
+	p.ProbeAll(context.Background())
+	require.Len(t, recorder.Events, 1)
+	require.True(t, strings.HasPrefix(<-recorder.Events, "Normal UpstreamReachable GET "+registry.URL+"/v2/ returned 200"))
+}
+
+func buildConfig(registryURL string, proxy *v1beta1.Proxy) *v1beta1.RegistryCacheConfig {
+	parsed, _ := url.Parse(registryURL)
+
+	return &v1beta1.RegistryCacheConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "default"},
+		Spec: v1beta1.RegistryCacheConfigSpec{
+			Upstream:  parsed.Host,
+			RemoteURL: ptr.To(registryURL),
+			Proxy:     proxy,
+		},
+	}
+}
+
+func fixFakeClient(t *testing.T, objects ...client.Object) client.Client {
+	s := runtime.NewScheme()
+	require.NoError(t, scheme.AddToScheme(s))
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	return fake.NewClientBuilder().
+		WithScheme(s).
+		WithObjects(objects...).
+		WithStatusSubresource(&v1beta1.RegistryCacheConfig{}).
+		Build()
+}
+
+func getCondition(t *testing.T, c client.Client) *metav1.Condition {
+	var config v1beta1.RegistryCacheConfig
+	require.NoError(t, c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "config"}, &config))
+
+	condition := meta.FindStatusCondition(config.Status.Conditions, string(v1beta1.ConditionTypeUpstreamReachable))
+	require.NotNil(t, condition)
+
+	return condition
+}
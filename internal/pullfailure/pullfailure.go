@@ -0,0 +1,121 @@
+package pullfailure
+
+import (
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/eventtime"
+	"github.com/kyma-project/registry-cache/internal/upstream"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ReasonErrImagePull is the reason of a failed image pull.
+	ReasonErrImagePull = "ErrImagePull"
+	// ReasonImagePullBackOff is the reason of a failed image pull which the kubelet retries later.
+	ReasonImagePullBackOff = "ImagePullBackOff"
+
+	// eventReasonFailed and eventReasonBackOff are the reasons of the kubelet Events for failed image pulls.
+	eventReasonFailed  = "Failed"
+	eventReasonBackOff = "BackOff"
+)
+
+var (
+	failedPattern  = regexp.MustCompile(`(?s)^Failed to pull image "([^"]+)"(?::\s*(.*))?$`)
+	backOffPattern = regexp.MustCompile(`(?s)^Back-off pulling image "([^"]+)"(?::\s*(.*))?$`)
+)
+
+// Failure is a failed image pull parsed from a kubelet Event.
+type Failure struct {
+	Image   string
+	Reason  string
+	Message string
+}
+
+// Upstream returns the normalized upstream of the image.
+func (f Failure) Upstream() string {
+	return upstream.FromImage(f.Image)
+}
+
+// FromEvent parses the Events which the kubelet emits for Pods when it fails to pull an image or backs off from pulling it.
+// Other Events are not parsed.
+func FromEvent(event *corev1.Event) (Failure, bool) {
+	if event.InvolvedObject.Kind != "Pod" || event.Type != corev1.EventTypeWarning {
+		return Failure{}, false
+	}
+
+	var pattern *regexp.Regexp
+	var reason string
+	switch event.Reason {
+	case eventReasonFailed:
+		pattern, reason = failedPattern, ReasonErrImagePull
+	case eventReasonBackOff:
+		pattern, reason = backOffPattern, ReasonImagePullBackOff
+	default:
+		return Failure{}, false
+	}
+
+	match := pattern.FindStringSubmatch(event.Message)
+	if match == nil {
+		return Failure{}, false
+	}
+
+	return Failure{Image: match[1], Reason: reason, Message: match[2]}, true
+}
+
+// Aggregate merges the pull failures of the Events which occurred after since per Pod and image, the latest first.
+func Aggregate(events []corev1.Event, since time.Time) []v1beta1.PullFailure {
+	type key struct{ namespace, pod, image string }
+
+	merged := map[key]*v1beta1.PullFailure{}
+	for _, event := range events {
+		failure, ok := FromEvent(&event)
+		if !ok {
+			continue
+		}
+		lastSeen := eventtime.LastSeen(&event)
+		if !lastSeen.After(since) {
+			continue
+		}
+
+		k := key{namespace: event.InvolvedObject.Namespace, pod: event.InvolvedObject.Name, image: failure.Image}
+		existing, ok := merged[k]
+		if !ok {
+			existing = &v1beta1.PullFailure{Namespace: k.namespace, PodName: k.pod, Image: k.image}
+			merged[k] = existing
+		}
+
+		existing.Count += max(event.Count, 1)
+		if lastSeen.After(existing.LastTimestamp.Time) {
+			existing.LastTimestamp = metav1.NewTime(lastSeen)
+			existing.Reason = failure.Reason
+			if failure.Message != "" {
+				existing.Message = failure.Message
+			}
+		} else if existing.Message == "" {
+			existing.Message = failure.Message
+		}
+	}
+
+	failures := make([]v1beta1.PullFailure, 0, len(merged))
+	for _, failure := range merged {
+		failures = append(failures, *failure)
+	}
+	sort.Slice(failures, func(i, j int) bool {
+		if !failures[i].LastTimestamp.Equal(&failures[j].LastTimestamp) {
+			return failures[i].LastTimestamp.After(failures[j].LastTimestamp.Time)
+		}
+		if failures[i].Namespace != failures[j].Namespace {
+			return failures[i].Namespace < failures[j].Namespace
+		}
+		if failures[i].PodName != failures[j].PodName {
+			return failures[i].PodName < failures[j].PodName
+		}
+		return failures[i].Image < failures[j].Image
+	})
+
+	return failures
+}
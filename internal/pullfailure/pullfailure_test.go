@@ -0,0 +1,126 @@
+package pullfailure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var now = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func fixEvent(namespace, pod, reason, message string, count int32, lastSeen time.Time) corev1.Event {
+	return corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: pod + ".17c", Namespace: namespace},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: namespace, Name: pod},
+		Type:           corev1.EventTypeWarning,
+		Reason:         reason,
+		Message:        message,
+		Count:          count,
+		LastTimestamp:  metav1.NewTime(lastSeen),
+	}
+}
+
+func TestFromEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    corev1.Event
+		want     Failure
+		wantOk   bool
+		upstream string
+	}{
+		{
+			name:     "failed pull",
+			event:    fixEvent("default", "nginx", "Failed", `Failed to pull image "nginx:1.27": rpc error: code = Unknown desc = 429 Too Many Requests`, 1, now),
+			want:     Failure{Image: "nginx:1.27", Reason: ReasonErrImagePull, Message: "rpc error: code = Unknown desc = 429 Too Many Requests"},
+			wantOk:   true,
+			upstream: "docker.io",
+		},
+		{
+			name:     "back-off",
+			event:    fixEvent("default", "app", "BackOff", `Back-off pulling image "quay.io/org/app:v1"`, 4, now),
+			want:     Failure{Image: "quay.io/org/app:v1", Reason: ReasonImagePullBackOff},
+			wantOk:   true,
+			upstream: "quay.io",
+		},
+		{
+			name:  "back-off of a crashing container",
+			event: fixEvent("default", "app", "BackOff", "Back-off restarting failed container app in pod app", 1, now),
+		},
+		{
+			name:  "failed mount",
+			event: fixEvent("default", "app", "Failed", "Error: secret \"app\" not found", 1, now),
+		},
+		{
+			name: "event of another kind",
+			event: func() corev1.Event {
+				event := fixEvent("default", "app", "Failed", `Failed to pull image "nginx"`, 1, now)
+				event.InvolvedObject.Kind = "Node"
+				return event
+			}(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := FromEvent(&tt.event)
+
+			require.Equal(t, tt.wantOk, ok)
+			require.Equal(t, tt.want, got)
+			if ok {
+				require.Equal(t, tt.upstream, got.Upstream())
+			}
+		})
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	events := []corev1.Event{
+		fixEvent("default", "nginx", "Failed", `Failed to pull image "nginx:1.27": 429 Too Many Requests`, 2, now.Add(-time.Minute*5)),
+		fixEvent("default", "nginx", "BackOff", `Back-off pulling image "nginx:1.27"`, 6, now.Add(-time.Minute)),
+		fixEvent("apps", "web", "Failed", `Failed to pull image "nginx:1.26": not found`, 1, now.Add(-time.Minute*3)),
+		fixEvent("apps", "old", "Failed", `Failed to pull image "nginx:1.25": not found`, 1, now.Add(-time.Hour*2)),
+		fixEvent("apps", "crashing", "BackOff", "Back-off restarting failed container", 9, now),
+	}
+
+	t.Run("merges the failures per pod and image", func(t *testing.T) {
+		failures := Aggregate(events, now.Add(-time.Hour))
+
+		require.Equal(t, []v1beta1.PullFailure{
+			{
+				Namespace:     "default",
+				PodName:       "nginx",
+				Image:         "nginx:1.27",
+				Reason:        ReasonImagePullBackOff,
+				Message:       "429 Too Many Requests",
+				Count:         8,
+				LastTimestamp: metav1.NewTime(now.Add(-time.Minute)),
+			},
+			{
+				Namespace:     "apps",
+				PodName:       "web",
+				Image:         "nginx:1.26",
+				Reason:        ReasonErrImagePull,
+				Message:       "not found",
+				Count:         1,
+				LastTimestamp: metav1.NewTime(now.Add(-time.Minute * 3)),
+			},
+		}, failures)
+	})
+
+	t.Run("sorts the failures by their last occurrence", func(t *testing.T) {
+		failures := Aggregate(events, now.Add(-time.Hour*3))
+
+		require.Len(t, failures, 3)
+		require.Equal(t, []string{"nginx", "web", "old"}, []string{failures[0].PodName, failures[1].PodName, failures[2].PodName})
+	})
+
+	t.Run("no events", func(t *testing.T) {
+		failures := Aggregate(nil, now.Add(-time.Hour))
+
+		require.Empty(t, failures)
+	})
+}
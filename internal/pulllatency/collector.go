@@ -0,0 +1,290 @@
+package pulllatency
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/eventtime"
+	"github.com/kyma-project/registry-cache/internal/upstream"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// DefaultInterval is the interval in which the Events of pulled images are read.
+	DefaultInterval = time.Minute * 5
+	// DefaultWindow is the period of the pulls summarized in the RegistryCacheConfig status.
+	DefaultWindow = time.Hour * 24
+
+	// maxSamples is the maximum number of pulls kept per upstream for the summary in the status.
+	maxSamples = 1000
+	// listPageSize is the number of Events read with one request.
+	listPageSize = 500
+)
+
+var (
+	labels = []string{"upstream", "cached"}
+
+	durationHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "registry_cache_image_pull_duration_seconds",
+		Help:    "Duration of the image pulls of Pods as reported by the kubelet, by upstream and whether a RegistryCacheConfig existed for the upstream.",
+		Buckets: prometheus.ExponentialBuckets(0.25, 2, 11),
+	}, labels)
+	sizeHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "registry_cache_image_pull_size_bytes",
+		Help:    "Size of the images pulled by Pods as reported by the kubelet, by upstream and whether a RegistryCacheConfig existed for the upstream.",
+		Buckets: prometheus.ExponentialBuckets(1024*1024, 4, 9),
+	}, labels)
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(durationHistogram, sizeHistogram)
+}
+
+// sample is a pull kept for the summary in the status.
+type sample struct {
+	time     time.Time
+	duration time.Duration
+	size     int64
+}
+
+// Collector periodically reads the Events of the images which the kubelet pulled for Pods, observes the pull
+// durations and image sizes in histograms by upstream, and summarizes the pulls of the upstream of every
+// RegistryCacheConfig in status.pullStatistics. It implements the manager.Runnable interface.
+type Collector struct {
+	client   client.Client
+	events   client.Reader
+	interval time.Duration
+	window   time.Duration
+	now      func() time.Time
+
+	samples  map[string][]sample
+	observed map[types.UID]int32
+	// observedSince is the time from which on the pulls were observed, the oldest Event of the first collection.
+	// The API server keeps Events for about an hour, so earlier pulls are unknown.
+	observedSince time.Time
+}
+
+// New constructs a Collector which reads the Events every interval and summarizes the pulls within the window.
+// The Events are listed with the reader, so that they need not be cached.
+func New(c client.Client, events client.Reader, interval, window time.Duration) *Collector {
+	return &Collector{
+		client:   c,
+		events:   events,
+		interval: interval,
+		window:   window,
+		now:      time.Now,
+		samples:  map[string][]sample{},
+		observed: map[types.UID]int32{},
+	}
+}
+
+// Start collects the pulls until the context is cancelled.
+func (c *Collector) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, c.CollectAll, c.interval)
+	return nil
+}
+
+// NeedLeaderElection implements the LeaderElectionRunnable interface, so that only the leader updates the status.
+func (*Collector) NeedLeaderElection() bool {
+	return true
+}
+
+// CollectAll reads the Events of pulled images once, observes the pulls which were not observed before,
+// and updates the status of all RegistryCacheConfigs.
+func (c *Collector) CollectAll(ctx context.Context) {
+	logger := log.FromContext(ctx).WithName("pull-latency")
+
+	var configs v1beta1.RegistryCacheConfigList
+	if err := c.client.List(ctx, &configs); err != nil {
+		logger.Error(err, "failed to list registry cache configs")
+		return
+	}
+
+	cached := map[string]bool{}
+	for _, config := range configs.Items {
+		if config.Spec.Upstream != "" {
+			cached[upstream.Normalize(config.Spec.Upstream)] = true
+		}
+	}
+
+	events, err := c.listEvents(ctx)
+	if err != nil {
+		logger.Error(err, "failed to list the events of pulled images")
+		return
+	}
+
+	now := c.now()
+	c.observe(events, cached, now)
+	window := c.effectiveWindow(now)
+
+	for _, config := range configs.Items {
+		if !config.DeletionTimestamp.IsZero() || config.Spec.Upstream == "" {
+			continue
+		}
+
+		statistics := summarize(c.samples[upstream.Normalize(config.Spec.Upstream)], window)
+		if err := c.updateStatus(ctx, client.ObjectKeyFromObject(&config), statistics); err != nil {
+			logger.Error(err, "failed to update the pull statistics", "namespace", config.Namespace, "name", config.Name)
+		}
+	}
+}
+
+// listEvents lists the Events of pulled images in pages, so that a cluster with many Pods is not read in one request.
+func (c *Collector) listEvents(ctx context.Context) ([]corev1.Event, error) {
+	var events []corev1.Event
+	continueToken := ""
+	for {
+		var page corev1.EventList
+		if err := c.events.List(ctx, &page,
+			client.MatchingFields{"involvedObject.kind": "Pod", "reason": eventReasonPulled},
+			client.Limit(listPageSize), client.Continue(continueToken)); err != nil {
+			return nil, err
+		}
+		events = append(events, page.Items...)
+
+		continueToken = page.Continue
+		if continueToken == "" {
+			return events, nil
+		}
+	}
+}
+
+// effectiveWindow returns the period of the summarized pulls, which is shorter than the window
+// until the pulls of the whole window were observed, for example after a restart of the controller.
+func (c *Collector) effectiveWindow(now time.Time) time.Duration {
+	return min(c.window, now.Sub(c.observedSince))
+}
+
+// observe records the pulls of the Events within the window. An Event which the kubelet updated for a repeated pull
+// is observed again. Pulls which left the window are dropped from the samples.
+func (c *Collector) observe(events []corev1.Event, cached map[string]bool, now time.Time) {
+	since := now.Add(-c.window)
+	if c.observedSince.IsZero() {
+		c.observedSince = now
+		for _, event := range events {
+			if lastSeen := eventtime.LastSeen(&event); lastSeen.Before(c.observedSince) {
+				c.observedSince = lastSeen
+			}
+		}
+	}
+
+	listed := make(map[types.UID]bool, len(events))
+	for _, event := range events {
+		pull, ok := FromEvent(&event)
+		if !ok {
+			continue
+		}
+
+		listed[event.UID] = true
+		count := max(event.Count, 1)
+		if c.observed[event.UID] >= count {
+			continue
+		}
+		c.observed[event.UID] = count
+
+		lastSeen := eventtime.LastSeen(&event)
+		if !lastSeen.After(since) {
+			continue
+		}
+
+		normalized := pull.Upstream()
+		values := []string{normalized, strconv.FormatBool(cached[normalized])}
+		durationHistogram.WithLabelValues(values...).Observe(pull.Duration.Seconds())
+		if pull.Size > 0 {
+			sizeHistogram.WithLabelValues(values...).Observe(float64(pull.Size))
+		}
+
+		c.samples[normalized] = append(c.samples[normalized], sample{time: lastSeen, duration: pull.Duration, size: pull.Size})
+	}
+
+	// Events expire in the API server, so the Events which are no longer listed cannot be observed again.
+	for uid := range c.observed {
+		if !listed[uid] {
+			delete(c.observed, uid)
+		}
+	}
+
+	for normalized, samples := range c.samples {
+		samples = slices.DeleteFunc(samples, func(s sample) bool { return !s.time.After(since) })
+		slices.SortFunc(samples, func(a, b sample) int { return a.time.Compare(b.time) })
+		if len(samples) > maxSamples {
+			samples = samples[len(samples)-maxSamples:]
+		}
+		if len(samples) == 0 {
+			delete(c.samples, normalized)
+			continue
+		}
+		c.samples[normalized] = samples
+	}
+}
+
+// updateStatus sets the pull statistics on the latest version of the config if they changed.
+func (c *Collector) updateStatus(ctx context.Context, key types.NamespacedName, statistics *v1beta1.PullStatistics) error {
+	var config v1beta1.RegistryCacheConfig
+	if err := c.client.Get(ctx, key, &config); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	if equality.Semantic.DeepEqual(config.Status.PullStatistics, statistics) {
+		return nil
+	}
+
+	original := config.DeepCopy()
+	config.Status.PullStatistics = statistics
+
+	if err := c.client.Status().Patch(ctx, &config, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})); err != nil {
+		return fmt.Errorf("error while patching status: %w", err)
+	}
+	return nil
+}
+
+// summarize computes the percentiles of the durations and sizes of the pulls. It returns nil if there are no pulls.
+func summarize(samples []sample, window time.Duration) *v1beta1.PullStatistics {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	durations := make([]time.Duration, 0, len(samples))
+	var sizes []int64
+	for _, s := range samples {
+		durations = append(durations, s.duration)
+		if s.size > 0 {
+			sizes = append(sizes, s.size)
+		}
+	}
+	slices.Sort(durations)
+	slices.Sort(sizes)
+
+	statistics := &v1beta1.PullStatistics{
+		Window:      metav1.Duration{Duration: window},
+		Pulls:       int32(len(samples)),
+		DurationP50: metav1.Duration{Duration: percentile(durations, 50)},
+		DurationP95: metav1.Duration{Duration: percentile(durations, 95)},
+	}
+	if len(sizes) > 0 {
+		statistics.SizeP50 = resource.NewQuantity(percentile(sizes, 50), resource.BinarySI)
+		statistics.SizeP95 = resource.NewQuantity(percentile(sizes, 95), resource.BinarySI)
+	}
+
+	return statistics
+}
+
+// percentile returns the value of the nearest rank in the sorted values.
+func percentile[T time.Duration | int64](sorted []T, p int) T {
+	rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
@@ -0,0 +1,175 @@
+package pulllatency
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCollectAll(t *testing.T) {
+	now := time.Date(2025, 3, 4, 9, 15, 0, 0, time.UTC)
+	newCollector := func(c client.Client) *Collector {
+		collector := New(c, c, DefaultInterval, time.Hour*2)
+		collector.now = func() time.Time { return now }
+		return collector
+	}
+
+	t.Run("summarizes the pulls of the upstream in the status", func(t *testing.T) {
+		c := fixFakeClient(t,
+			buildConfig("gcr", "gcr.io"),
+			buildEvent("app-1", "gcr.io/org/app:v1", "1s", 100*1024*1024, now.Add(-time.Minute)),
+			buildEvent("app-2", "gcr.io/org/app:v1", "2s", 200*1024*1024, now.Add(-time.Minute*10)),
+			buildEvent("app-3", "gcr.io/org/app:v2", "10s", 0, now.Add(-time.Minute*30)),
+			buildEvent("app-4", "quay.io/org/app:v1", "30s", 0, now.Add(-time.Minute)),
+			buildEvent("app-5", "gcr.io/org/app:v0", "1m", 0, now.Add(-time.Hour*3)),
+		)
+		collector := newCollector(c)
+
+		collector.CollectAll(context.Background())
+
+		require.Equal(t, &v1beta1.PullStatistics{
+			Window:      metav1.Duration{Duration: time.Hour * 2},
+			Pulls:       3,
+			DurationP50: metav1.Duration{Duration: time.Second * 2},
+			DurationP95: metav1.Duration{Duration: time.Second * 10},
+			SizeP50:     ptr.To(resource.MustParse("100Mi")),
+			SizeP95:     ptr.To(resource.MustParse("200Mi")),
+		}, getConfig(t, c, "gcr").Status.PullStatistics)
+		require.Equal(t, uint64(3), sampleCount(t, durationHistogram, prometheus.Labels{"upstream": "gcr.io", "cached": "true"}))
+		require.Equal(t, uint64(2), sampleCount(t, sizeHistogram, prometheus.Labels{"upstream": "gcr.io", "cached": "true"}))
+		require.Equal(t, uint64(1), sampleCount(t, durationHistogram, prometheus.Labels{"upstream": "quay.io", "cached": "false"}))
+
+		collector.CollectAll(context.Background())
+		require.Equal(t, int32(3), getConfig(t, c, "gcr").Status.PullStatistics.Pulls, "every pull must be observed once")
+		require.Equal(t, uint64(3), sampleCount(t, durationHistogram, prometheus.Labels{"upstream": "gcr.io", "cached": "true"}))
+
+		var event corev1.Event
+		require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "app-1"}, &event))
+		event.Count = 2
+		require.NoError(t, c.Update(context.Background(), &event))
+		collector.CollectAll(context.Background())
+		require.Equal(t, int32(4), getConfig(t, c, "gcr").Status.PullStatistics.Pulls, "a repeated pull must be observed again")
+	})
+
+	t.Run("reports the period of the observed pulls as the window", func(t *testing.T) {
+		c := fixFakeClient(t,
+			buildConfig("gcr", "gcr.io"),
+			buildEvent("app-1", "gcr.io/org/app:v1", "1s", 0, now.Add(-time.Minute*40)),
+		)
+		collector := newCollector(c)
+
+		collector.CollectAll(context.Background())
+		require.Equal(t, time.Minute*40, getConfig(t, c, "gcr").Status.PullStatistics.Window.Duration,
+			"the pulls before the oldest Event are unknown")
+
+		now = now.Add(time.Hour)
+		require.NoError(t, c.Create(context.Background(), buildEvent("app-2", "gcr.io/org/app:v2", "1s", 0, now.Add(-time.Minute))))
+		collector.CollectAll(context.Background())
+		require.Equal(t, time.Hour+time.Minute*40, getConfig(t, c, "gcr").Status.PullStatistics.Window.Duration)
+
+		now = now.Add(time.Hour)
+		collector.CollectAll(context.Background())
+		require.Equal(t, time.Hour*2, getConfig(t, c, "gcr").Status.PullStatistics.Window.Duration)
+	})
+
+	t.Run("removes the statistics when the pulls leave the window", func(t *testing.T) {
+		c := fixFakeClient(t,
+			buildConfig("ghcr", "ghcr.io"),
+			buildEvent("web-1", "ghcr.io/org/web:v1", "4s", 0, now.Add(-time.Minute)),
+		)
+		collector := newCollector(c)
+
+		collector.CollectAll(context.Background())
+		require.NotNil(t, getConfig(t, c, "ghcr").Status.PullStatistics)
+
+		now = now.Add(time.Hour * 3)
+		collector.CollectAll(context.Background())
+		require.Nil(t, getConfig(t, c, "ghcr").Status.PullStatistics)
+	})
+}
+
+func TestSummarize(t *testing.T) {
+	var samples []sample
+	for i := 1; i <= 20; i++ {
+		samples = append(samples, sample{duration: time.Duration(i) * time.Second})
+	}
+
+	statistics := summarize(samples, time.Hour)
+
+	require.Equal(t, time.Second*10, statistics.DurationP50.Duration)
+	require.Equal(t, time.Second*19, statistics.DurationP95.Duration)
+	require.Nil(t, statistics.SizeP50, "no image size is expected without sizes")
+	require.Nil(t, summarize(nil, time.Hour))
+}
+
+func buildConfig(name, upstream string) *v1beta1.RegistryCacheConfig {
+	return &v1beta1.RegistryCacheConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       v1beta1.RegistryCacheConfigSpec{Upstream: upstream},
+	}
+}
+
+func buildEvent(pod, image, duration string, size int64, lastSeen time.Time) *corev1.Event {
+	message := fmt.Sprintf("Successfully pulled image %q in %s (%s including waiting)", image, duration, duration)
+	if size > 0 {
+		message += fmt.Sprintf(". Image size: %d bytes.", size)
+	}
+
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: pod, Namespace: "default", UID: types.UID(pod)},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: pod},
+		Type:           corev1.EventTypeNormal,
+		Reason:         "Pulled",
+		Message:        message,
+		Count:          1,
+		LastTimestamp:  metav1.NewTime(lastSeen),
+	}
+}
+
+func fixFakeClient(t *testing.T, objects ...client.Object) client.Client {
+	s := runtime.NewScheme()
+	require.NoError(t, scheme.AddToScheme(s))
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	return fake.NewClientBuilder().
+		WithScheme(s).
+		WithObjects(objects...).
+		WithStatusSubresource(&v1beta1.RegistryCacheConfig{}).
+		WithIndex(&corev1.Event{}, "involvedObject.kind", func(obj client.Object) []string {
+			return []string{obj.(*corev1.Event).InvolvedObject.Kind}
+		}).
+		WithIndex(&corev1.Event{}, "reason", func(obj client.Object) []string {
+			return []string{obj.(*corev1.Event).Reason}
+		}).
+		Build()
+}
+
+func getConfig(t *testing.T, c client.Client, name string) v1beta1.RegistryCacheConfig {
+	var config v1beta1.RegistryCacheConfig
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: name}, &config))
+	return config
+}
+
+func sampleCount(t *testing.T, histogram *prometheus.HistogramVec, labels prometheus.Labels) uint64 {
+	observer, err := histogram.GetMetricWith(labels)
+	require.NoError(t, err)
+
+	var metric dto.Metric
+	require.NoError(t, observer.(prometheus.Metric).Write(&metric))
+	return metric.GetHistogram().GetSampleCount()
+}
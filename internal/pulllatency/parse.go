@@ -0,0 +1,59 @@
+package pulllatency
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/kyma-project/registry-cache/internal/upstream"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// eventReasonPulled is the reason of the kubelet Events for pulled images and for images which are already present on the node.
+const eventReasonPulled = "Pulled"
+
+// pulledPattern matches the messages of all kubelet versions since Kubernetes 1.24, for example:
+//
+//	Successfully pulled image "nginx:1.27" in 2.5s (3.1s including waiting). Image size: 72099410 bytes.
+var pulledPattern = regexp.MustCompile(`^Successfully pulled image "([^"]+)" in (\S+?)(?: \((\S+) including waiting\))?(?:\. Image size: (\d+) bytes)?\.?$`)
+
+// Pull is a successful image pull parsed from a kubelet Event.
+type Pull struct {
+	Image string
+	// Duration is the time the kubelet spent pulling the image, without waiting for other pulls.
+	Duration time.Duration
+	// Size is the size of the image in bytes, or 0 if the kubelet does not report it.
+	Size int64
+}
+
+// Upstream returns the normalized upstream of the image.
+func (p Pull) Upstream() string {
+	return upstream.FromImage(p.Image)
+}
+
+// FromEvent parses the Events which the kubelet emits for Pods when it pulled an image. Events for images which
+// are already present on the node, and all other Events, are not parsed.
+func FromEvent(event *corev1.Event) (Pull, bool) {
+	if event.InvolvedObject.Kind != "Pod" || event.Reason != eventReasonPulled {
+		return Pull{}, false
+	}
+
+	match := pulledPattern.FindStringSubmatch(event.Message)
+	if match == nil {
+		return Pull{}, false
+	}
+
+	duration, err := time.ParseDuration(match[2])
+	if err != nil {
+		return Pull{}, false
+	}
+
+	pull := Pull{Image: match[1], Duration: duration}
+	if match[4] != "" {
+		if size, err := strconv.ParseInt(match[4], 10, 64); err == nil {
+			pull.Size = size
+		}
+	}
+
+	return pull, true
+}
@@ -0,0 +1,68 @@
+package pulllatency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestFromEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		want     Pull
+		wantOk   bool
+		upstream string
+	}{
+		{
+			name:     "pull with image size",
+			message:  `Successfully pulled image "nginx:1.27" in 2.512s (3.1s including waiting). Image size: 72099410 bytes.`,
+			want:     Pull{Image: "nginx:1.27", Duration: time.Millisecond * 2512, Size: 72099410},
+			wantOk:   true,
+			upstream: "docker.io",
+		},
+		{
+			name:     "pull without image size",
+			message:  `Successfully pulled image "europe-docker.pkg.dev/org/app:v1" in 1m3.5s (1m3.5s including waiting)`,
+			want:     Pull{Image: "europe-docker.pkg.dev/org/app:v1", Duration: time.Second*63 + time.Millisecond*500},
+			wantOk:   true,
+			upstream: "europe-docker.pkg.dev",
+		},
+		{
+			name:     "pull without waiting time",
+			message:  `Successfully pulled image "quay.io/org/app@sha256:4f2d" in 850ms`,
+			want:     Pull{Image: "quay.io/org/app@sha256:4f2d", Duration: time.Millisecond * 850},
+			wantOk:   true,
+			upstream: "quay.io",
+		},
+		{
+			name:    "image already present",
+			message: `Container image "nginx:1.27" already present on machine`,
+		},
+		{
+			name:    "invalid duration",
+			message: `Successfully pulled image "nginx:1.27" in soon`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &corev1.Event{
+				InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "app"},
+				Type:           corev1.EventTypeNormal,
+				Reason:         "Pulled",
+				Message:        tt.message,
+			}
+
+			got, ok := FromEvent(event)
+
+			require.Equal(t, tt.wantOk, ok)
+			require.Equal(t, tt.want, got)
+			if ok {
+				require.Equal(t, tt.upstream, got.Upstream())
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package recommendation
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/upstream"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// MaxRecommendations is the maximum number of recommended upstreams.
+	MaxRecommendations = 10
+
+	// pullFrequencyWindow is the period of the estimated pull frequency.
+	pullFrequencyWindow = time.Hour * 24
+)
+
+// Recommend inventories the images of the containers and init containers of the scheduled Pods, groups them by
+// the normalized upstream, and ranks the upstreams by the estimated number of pulls and the number of nodes.
+// A node is assumed to pull an image once, when the first Pod using it starts on the node. Upstreams for which
+// skip returns true are not recommended. At most MaxRecommendations upstreams are returned.
+func Recommend(pods []corev1.Pod, now time.Time, skip func(upstream string) bool) []v1beta1.UpstreamRecommendation {
+	inventory := NewInventory()
+	inventory.Add(pods...)
+	return inventory.Recommend(now, skip)
+}
+
+// pull is the pull of an image on a node.
+type pull struct{ node, image string }
+
+// Inventory collects the image pulls of Pods which are added page by page, so that the Pods of a large cluster
+// are not kept in memory at once. Its size grows with the distinct images per node, not with the number of Pods.
+type Inventory struct {
+	// firstStart is the start of the first Pod using the image on the node.
+	firstStart map[pull]time.Time
+}
+
+// NewInventory returns an empty Inventory.
+func NewInventory() *Inventory {
+	return &Inventory{firstStart: map[pull]time.Time{}}
+}
+
+// Add records the image pulls of the scheduled Pods.
+func (i *Inventory) Add(pods ...corev1.Pod) {
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+
+		started := pod.CreationTimestamp.Time
+		if pod.Status.StartTime != nil {
+			started = pod.Status.StartTime.Time
+		}
+
+		for _, image := range images(&pod) {
+			key := pull{node: pod.Spec.NodeName, image: image}
+			if first, ok := i.firstStart[key]; !ok || started.Before(first) {
+				i.firstStart[key] = started
+			}
+		}
+	}
+}
+
+// Recommend ranks the upstreams of the recorded image pulls like the package-level Recommend.
+func (i *Inventory) Recommend(now time.Time, skip func(upstream string) bool) []v1beta1.UpstreamRecommendation {
+	type usage struct {
+		recommendation v1beta1.UpstreamRecommendation
+		nodes          map[string]bool
+		imageNodes     map[string]int
+	}
+
+	since := now.Add(-pullFrequencyWindow)
+	usages := map[string]*usage{}
+	for key, started := range i.firstStart {
+		normalized := upstream.FromImage(key.image)
+		if skip != nil && skip(normalized) {
+			continue
+		}
+
+		u, ok := usages[normalized]
+		if !ok {
+			u = &usage{
+				recommendation: v1beta1.UpstreamRecommendation{Upstream: normalized},
+				nodes:          map[string]bool{},
+				imageNodes:     map[string]int{},
+			}
+			usages[normalized] = u
+		}
+
+		u.nodes[key.node] = true
+		u.imageNodes[key.image]++
+		u.recommendation.EstimatedPulls++
+		if started.After(since) {
+			u.recommendation.EstimatedPullsPerDay++
+		}
+	}
+
+	recommendations := make([]v1beta1.UpstreamRecommendation, 0, len(usages))
+	for _, u := range usages {
+		u.recommendation.Nodes = int32(len(u.nodes))
+		u.recommendation.Images = int32(len(u.imageNodes))
+		u.recommendation.ExampleImage = mostSpread(u.imageNodes)
+		recommendations = append(recommendations, u.recommendation)
+	}
+
+	sort.Slice(recommendations, func(i, j int) bool {
+		a, b := recommendations[i], recommendations[j]
+		if a.EstimatedPulls != b.EstimatedPulls {
+			return a.EstimatedPulls > b.EstimatedPulls
+		}
+		if a.Nodes != b.Nodes {
+			return a.Nodes > b.Nodes
+		}
+		return a.Upstream < b.Upstream
+	})
+
+	if len(recommendations) > MaxRecommendations {
+		recommendations = recommendations[:MaxRecommendations]
+	}
+
+	return recommendations
+}
+
+// images returns the distinct images of the containers and init containers of the Pod.
+func images(pod *corev1.Pod) []string {
+	seen := map[string]bool{}
+	var result []string
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, container := range containers {
+			if container.Image == "" || seen[container.Image] {
+				continue
+			}
+			seen[container.Image] = true
+			result = append(result, container.Image)
+		}
+	}
+
+	return result
+}
+
+// mostSpread returns the image which runs on the most nodes, the first in lexical order on a tie.
+func mostSpread(imageNodes map[string]int) string {
+	var example string
+	for image, nodes := range imageNodes {
+		if example == "" || nodes > imageNodes[example] || (nodes == imageNodes[example] && image < example) {
+			example = image
+		}
+	}
+
+	return example
+}
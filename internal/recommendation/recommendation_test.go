@@ -0,0 +1,97 @@
+package recommendation
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var now = time.Date(2025, 6, 2, 8, 0, 0, 0, time.UTC)
+
+func fixPod(name, node string, started time.Time, images ...string) corev1.Pod {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(started.Add(-time.Second))},
+		Spec:       corev1.PodSpec{NodeName: node},
+		Status:     corev1.PodStatus{StartTime: &metav1.Time{Time: started}},
+	}
+	for i, image := range images {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: fmt.Sprintf("c%d", i), Image: image})
+	}
+
+	return pod
+}
+
+func TestRecommend(t *testing.T) {
+	recent := now.Add(-time.Hour)
+	old := now.Add(-time.Hour * 72)
+
+	pods := []corev1.Pod{
+		// Docker Hub short names and the docker.io host are one upstream.
+		fixPod("web-1", "node-1", recent, "nginx:1.27", "docker.io/library/redis:7"),
+		fixPod("web-2", "node-2", recent, "nginx:1.27"),
+		fixPod("web-3", "node-3", old, "index.docker.io/library/nginx:1.27"),
+		// A second Pod with the same image on the same node does not pull again.
+		fixPod("web-4", "node-1", recent, "nginx:1.27"),
+		fixPod("api-1", "node-1", old, "ghcr.io/org/api:v1"),
+		fixPod("api-2", "node-2", old, "ghcr.io/org/api:v1"),
+		fixPod("cached-1", "node-1", recent, "quay.io/org/app:v1"),
+		fixPod("pending", "", recent, "registry.example.com/app:v1"),
+	}
+	pods[5].Spec.InitContainers = []corev1.Container{{Name: "init", Image: "GHCR.io/org/init:v1"}}
+
+	recommendations := Recommend(pods, now, func(upstream string) bool { return upstream == "quay.io" })
+
+	require.Equal(t, []v1beta1.UpstreamRecommendation{
+		{
+			Upstream:             "docker.io",
+			ExampleImage:         "nginx:1.27",
+			Images:               3,
+			Nodes:                3,
+			EstimatedPulls:       4,
+			EstimatedPullsPerDay: 3,
+		},
+		{
+			Upstream:             "ghcr.io",
+			ExampleImage:         "ghcr.io/org/api:v1",
+			Images:               2,
+			Nodes:                2,
+			EstimatedPulls:       3,
+			EstimatedPullsPerDay: 0,
+		},
+	}, recommendations)
+}
+
+func TestRecommendLimit(t *testing.T) {
+	var pods []corev1.Pod
+	for i := range MaxRecommendations + 5 {
+		pods = append(pods, fixPod(fmt.Sprintf("app-%d", i), "node-1", now, fmt.Sprintf("registry-%02d.example.com/app:v1", i)))
+	}
+
+	recommendations := Recommend(pods, now, nil)
+
+	require.Len(t, recommendations, MaxRecommendations)
+	require.Equal(t, "registry-00.example.com", recommendations[0].Upstream, "ties must be ranked by upstream")
+}
+
+func TestInventory(t *testing.T) {
+	pods := []corev1.Pod{
+		fixPod("web-1", "node-1", now.Add(-time.Hour), "nginx:1.27"),
+		fixPod("web-2", "node-2", now.Add(-time.Hour*72), "nginx:1.27"),
+		fixPod("api-1", "node-1", now.Add(-time.Hour), "ghcr.io/org/api:v1"),
+		// The first Pod of the image on the node is only in a later page.
+		fixPod("web-3", "node-1", now.Add(-time.Hour*72), "nginx:1.27"),
+	}
+
+	inventory := NewInventory()
+	for _, page := range [][]corev1.Pod{pods[:2], pods[2:3], pods[3:]} {
+		inventory.Add(page...)
+	}
+
+	require.Equal(t, Recommend(pods, now, nil), inventory.Recommend(now, nil))
+	require.Equal(t, int32(0), inventory.Recommend(now, nil)[0].EstimatedPullsPerDay, "the earliest start on a node must be kept")
+}
@@ -0,0 +1,110 @@
+package upstream
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// DockerHub is the canonical upstream of Docker Hub.
+const DockerHub = "docker.io"
+
+// aliases maps the well-known alternative hosts of a registry to its canonical upstream.
+var aliases = map[string]string{
+	"index.docker.io":         DockerHub,
+	"registry-1.docker.io":    DockerHub,
+	"registry.hub.docker.com": DockerHub,
+}
+
+// defaultPorts are the ports which are implied by the URL scheme. Upstreams without a scheme are accessed over HTTPS.
+var defaultPorts = map[string]string{
+	"https": "443",
+	"http":  "80",
+}
+
+// Normalize returns the canonical form of an upstream in the `<host>[:<port>]` format, so that different spellings
+// of the same registry compare equal. The host is lower-cased, a trailing dot and the default HTTPS port are removed,
+// and well-known aliases are replaced by the canonical upstream, for example, `registry-1.docker.io` by `docker.io`.
+func Normalize(upstream string) string {
+	return normalize(upstream, "https")
+}
+
+// FromURL returns the normalized upstream of a remote URL. The default port of the URL scheme is removed.
+func FromURL(rawURL string) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse url: %w", err)
+	}
+	if parsed.Host == "" {
+		return "", fmt.Errorf("url %q has no host", rawURL)
+	}
+
+	return normalize(parsed.Host, strings.ToLower(parsed.Scheme)), nil
+}
+
+// FromImage returns the normalized upstream of an image reference. Like the container runtimes, it treats the first
+// path component as the registry only if it contains a dot or a port or is `localhost`, and defaults to Docker Hub otherwise.
+func FromImage(image string) string {
+	first, _, found := strings.Cut(image, "/")
+	if !found || (!strings.ContainsAny(first, ".:") && first != "localhost") {
+		return DockerHub
+	}
+
+	return Normalize(first)
+}
+
+// Host returns the host of an upstream without port and without the brackets of an IPv6 literal,
+// as expected by DNS lookups.
+func Host(upstream string) string {
+	host, _ := splitHostPort(strings.TrimSpace(upstream))
+
+	return host
+}
+
+func normalize(hostPort, scheme string) string {
+	host, port := splitHostPort(strings.TrimSpace(hostPort))
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	if canonical, ok := aliases[host]; ok {
+		host = canonical
+	}
+	if port == defaultPorts[scheme] {
+		port = ""
+	}
+
+	return joinHostPort(host, port)
+}
+
+// splitHostPort splits `host`, `host:port`, `[ipv6]` and `[ipv6]:port`. Unlike net.SplitHostPort it accepts
+// a missing port, and it leaves unbracketed IPv6 literals intact.
+func splitHostPort(hostPort string) (string, string) {
+	if strings.HasPrefix(hostPort, "[") {
+		end := strings.Index(hostPort, "]")
+		if end < 0 {
+			return hostPort, ""
+		}
+		host, rest := hostPort[1:end], hostPort[end+1:]
+		if port, ok := strings.CutPrefix(rest, ":"); ok {
+			return host, port
+		}
+		return host, ""
+	}
+
+	if strings.Count(hostPort, ":") == 1 {
+		host, port, _ := strings.Cut(hostPort, ":")
+		return host, port
+	}
+
+	return hostPort, ""
+}
+
+func joinHostPort(host, port string) string {
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port == "" {
+		return host
+	}
+
+	return host + ":" + port
+}
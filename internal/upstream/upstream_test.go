@@ -0,0 +1,99 @@
+package upstream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	for _, tc := range []struct {
+		upstream string
+		expected string
+	}{
+		{upstream: "quay.io", expected: "quay.io"},
+		{upstream: "Quay.IO", expected: "quay.io"},
+		{upstream: "quay.io.", expected: "quay.io"},
+		{upstream: "quay.io:443", expected: "quay.io"},
+		{upstream: "quay.io:5000", expected: "quay.io:5000"},
+		{upstream: "quay.io:80", expected: "quay.io:80"},
+		{upstream: "docker.io", expected: "docker.io"},
+		{upstream: "Docker.IO", expected: "docker.io"},
+		{upstream: "index.docker.io", expected: "docker.io"},
+		{upstream: "registry-1.docker.io", expected: "docker.io"},
+		{upstream: "registry-1.docker.io:443", expected: "docker.io"},
+		{upstream: "registry.hub.docker.com", expected: "docker.io"},
+		{upstream: "[FD00::1]:5000", expected: "[fd00::1]:5000"},
+		{upstream: "[fd00::1]:443", expected: "[fd00::1]"},
+		{upstream: "[fd00::1]", expected: "[fd00::1]"},
+		{upstream: "fd00::1", expected: "[fd00::1]"},
+	} {
+		t.Run(tc.upstream, func(t *testing.T) {
+			require.Equal(t, tc.expected, Normalize(tc.upstream))
+		})
+	}
+}
+
+func TestFromImage(t *testing.T) {
+	for _, tc := range []struct {
+		image    string
+		expected string
+	}{
+		{image: "nginx", expected: "docker.io"},
+		{image: "nginx:1.27", expected: "docker.io"},
+		{image: "library/nginx:1.27", expected: "docker.io"},
+		{image: "docker.io/library/nginx:1.27", expected: "docker.io"},
+		{image: "index.docker.io/library/nginx", expected: "docker.io"},
+		{image: "Quay.io/prometheus/node-exporter@sha256:0a2b4c6d", expected: "quay.io"},
+		{image: "my-registry.io:5000/app:1.0", expected: "my-registry.io:5000"},
+		{image: "localhost/app", expected: "localhost"},
+		{image: "[fd00::1]:5000/app", expected: "[fd00::1]:5000"},
+	} {
+		t.Run(tc.image, func(t *testing.T) {
+			require.Equal(t, tc.expected, FromImage(tc.image))
+		})
+	}
+}
+
+func TestFromURL(t *testing.T) {
+	for _, tc := range []struct {
+		url      string
+		expected string
+	}{
+		{url: "https://quay.io", expected: "quay.io"},
+		{url: "https://quay.io:443/", expected: "quay.io"},
+		{url: "http://quay.io:80", expected: "quay.io"},
+		{url: "http://quay.io:443", expected: "quay.io:443"},
+		{url: "https://registry-1.docker.io", expected: "docker.io"},
+		{url: "http://[fd00::1]:5000", expected: "[fd00::1]:5000"},
+		{url: "https://[fd00::1]", expected: "[fd00::1]"},
+	} {
+		t.Run(tc.url, func(t *testing.T) {
+			actual, err := FromURL(tc.url)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+
+	t.Run("url without host", func(t *testing.T) {
+		_, err := FromURL("quay.io")
+		require.Error(t, err)
+	})
+}
+
+func TestHost(t *testing.T) {
+	for _, tc := range []struct {
+		upstream string
+		expected string
+	}{
+		{upstream: "quay.io", expected: "quay.io"},
+		{upstream: "quay.io:5000", expected: "quay.io"},
+		{upstream: "[fd00::1]:5000", expected: "fd00::1"},
+		{upstream: "[fd00::1]", expected: "fd00::1"},
+		{upstream: "fd00::1", expected: "fd00::1"},
+	} {
+		t.Run(tc.upstream, func(t *testing.T) {
+			require.Equal(t, tc.expected, Host(tc.upstream))
+		})
+	}
+}
@@ -13,9 +13,9 @@ import (
 )
 
 type BuildUpdateCABundleOpts struct {
-	// Name of the validating webhook configuration to be updated
+	// Name of the webhook configuration to be updated
 	Name string
-	// CABundle the validating webhook configuration webhooks will be updated with
+	// CABundle the webhook configuration webhooks will be updated with
 	CABundle []byte
 	// FieldManager the name of the filed manager for patch operation
 	FieldManager string
@@ -27,43 +27,83 @@ func BuildUpdateCABundle(
 	rtClient client.Client,
 	opts BuildUpdateCABundleOpts) func() error {
 
+	return buildUpdateWebhookCABundle(ctx, rtClient, opts, "ValidatingWebhookConfiguration", "validating webhook configuration",
+		func(webhookConfig *admissionregistration.ValidatingWebhookConfiguration) []*admissionregistration.WebhookClientConfig {
+			clientConfigs := make([]*admissionregistration.WebhookClientConfig, 0, len(webhookConfig.Webhooks))
+			for i := range webhookConfig.Webhooks {
+				clientConfigs = append(clientConfigs, &webhookConfig.Webhooks[i].ClientConfig)
+			}
+			return clientConfigs
+		})
+}
+
+// BuildUpdateMutatingCABundle - builds a function that will update certificate authority of the mutating webhook configuration
+func BuildUpdateMutatingCABundle(
+	ctx context.Context,
+	rtClient client.Client,
+	opts BuildUpdateCABundleOpts) func() error {
+
+	return buildUpdateWebhookCABundle(ctx, rtClient, opts, "MutatingWebhookConfiguration", "mutating webhook configuration",
+		func(webhookConfig *admissionregistration.MutatingWebhookConfiguration) []*admissionregistration.WebhookClientConfig {
+			clientConfigs := make([]*admissionregistration.WebhookClientConfig, 0, len(webhookConfig.Webhooks))
+			for i := range webhookConfig.Webhooks {
+				clientConfigs = append(clientConfigs, &webhookConfig.Webhooks[i].ClientConfig)
+			}
+			return clientConfigs
+		})
+}
+
+// webhookConfiguration is a pointer to a validating or mutating webhook configuration.
+type webhookConfiguration[T any] interface {
+	*T
+	client.Object
+}
+
+// buildUpdateWebhookCABundle builds the update of the CA bundle of the webhook configuration of the given kind,
+// clientConfigs returns the client configs of all webhooks of the configuration.
+func buildUpdateWebhookCABundle[T any, PT webhookConfiguration[T]](
+	ctx context.Context,
+	rtClient client.Client,
+	opts BuildUpdateCABundleOpts,
+	kind, description string,
+	clientConfigs func(PT) []*admissionregistration.WebhookClientConfig) func() error {
+
 	logger := slog.Default()
 	return func() error {
 		getCtx, cancelGet := context.WithTimeout(ctx, 5*time.Second)
 		defer cancelGet()
 
-		var validatingWebhook admissionregistration.ValidatingWebhookConfiguration
+		webhookConfig := PT(new(T))
 		if err := rtClient.Get(
 			getCtx,
 			client.ObjectKey{Name: opts.Name},
-			&validatingWebhook); err != nil {
-			return fmt.Errorf("unable to get validating webhook configuration: %w", err)
+			webhookConfig); err != nil {
+			return fmt.Errorf("unable to get %s: %w", description, err)
 		}
 
 		var updated bool
-		for i := 0; i < len(validatingWebhook.Webhooks); i++ {
-			if bytes.Equal(opts.CABundle, validatingWebhook.Webhooks[i].ClientConfig.CABundle) {
+		for _, clientConfig := range clientConfigs(webhookConfig) {
+			if bytes.Equal(opts.CABundle, clientConfig.CABundle) {
 				continue
 			}
-			validatingWebhook.Webhooks[i].ClientConfig.CABundle = opts.CABundle
+			clientConfig.CABundle = opts.CABundle
 			updated = true
 		}
 
 		if !updated {
-			logger.Info("validating webhook configuration up to date")
+			logger.Info(description + " up to date")
 			return nil
 		}
 
-		validatingWebhook.Kind = "ValidatingWebhookConfiguration"
-		validatingWebhook.APIVersion = "admissionregistration.k8s.io/v1"
-		validatingWebhook.ManagedFields = nil
+		webhookConfig.GetObjectKind().SetGroupVersionKind(admissionregistration.SchemeGroupVersion.WithKind(kind))
+		webhookConfig.SetManagedFields(nil)
 
 		patchCtx, cancelPatch := context.WithTimeout(ctx, 5*time.Second)
 		defer cancelPatch()
 
-		logger.Info("attempting to patch validating webhook configuration", "name", validatingWebhook.Name)
+		logger.Info("attempting to patch "+description, "name", webhookConfig.GetName())
 
-		return rtClient.Patch(patchCtx, &validatingWebhook, client.Apply, &client.PatchOptions{ //nolint:staticcheck
+		return rtClient.Patch(patchCtx, webhookConfig, client.Apply, &client.PatchOptions{ //nolint:staticcheck
 			FieldManager: opts.FieldManager,
 			Force:        ptr.To(true),
 		})
@@ -125,3 +125,88 @@ func buildPatchFake(c *admissionregistration.ValidatingWebhookConfiguration) fun
 		return nil
 	}
 }
+
+func testMutatingWhCfg(name string, caBundle []byte) admissionregistration.MutatingWebhookConfiguration {
+	return admissionregistration.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Webhooks: []admissionregistration.MutatingWebhook{
+			{
+				ClientConfig: admissionregistration.WebhookClientConfig{
+					CABundle: caBundle,
+				},
+			},
+		},
+	}
+}
+
+func Test_BuildUpdateMutatingCABundle_get_error(t *testing.T) {
+	ctx := context.Background()
+	scheme := testScheme(t)
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		Build()
+
+	err := certificate.BuildUpdateMutatingCABundle(ctx, fakeClient, certificate.BuildUpdateCABundleOpts{
+		Name:     "test-me",
+		CABundle: []byte("updated"),
+	})()
+
+	assert.ErrorContains(t, err, "unable to get mutating webhook configuration")
+}
+
+func Test_BuildUpdateMutatingCABundle_up_to_date(t *testing.T) {
+	ctx := context.Background()
+	scheme := testScheme(t)
+
+	mWhCfg := testMutatingWhCfg("test-me", []byte("updated"))
+
+	// patch of the default fake client fails for server side apply, so no error means no patch was sent
+	fakeClient := fake.NewClientBuilder().
+		WithObjects(&mWhCfg).
+		WithScheme(scheme).
+		Build()
+
+	err := certificate.BuildUpdateMutatingCABundle(ctx, fakeClient, certificate.BuildUpdateCABundleOpts{
+		Name:     "test-me",
+		CABundle: []byte("updated"),
+	})()
+
+	assert.NoError(t, err)
+}
+
+func Test_BuildUpdateMutatingCABundle(t *testing.T) {
+	ctx := context.Background()
+	scheme := testScheme(t)
+
+	mWhCfg := testMutatingWhCfg("test-me", []byte("test-me"))
+
+	fakeClient := fake.NewClientBuilder().
+		WithObjects(&mWhCfg).
+		WithScheme(scheme).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(ctx context.Context, clnt client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				if patch.Type() != types.ApplyPatchType {
+					return clnt.Patch(ctx, obj, patch, opts...)
+				}
+
+				cfg, ok := obj.(*admissionregistration.MutatingWebhookConfiguration)
+				if !ok {
+					return fmt.Errorf("failed to cast object to mutating webhook configuration")
+				}
+
+				mWhCfg = *cfg
+				return nil
+			},
+		}).Build()
+
+	err := certificate.BuildUpdateMutatingCABundle(ctx, fakeClient, certificate.BuildUpdateCABundleOpts{
+		Name:     "test-me",
+		CABundle: []byte("updated"),
+	})()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("updated"), mWhCfg.Webhooks[0].ClientConfig.CABundle)
+}
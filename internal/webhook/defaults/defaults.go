@@ -0,0 +1,53 @@
+package defaults
+
+import (
+	registrycacheext "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry"
+	registryutils "github.com/gardener/gardener-extension-registry-cache/pkg/utils/registry"
+	registrycache "github.com/kyma-project/registry-cache/api/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+)
+
+// DefaultVolumeSize is the size of the registry cache volume used when spec.volume.size is not set.
+var DefaultVolumeSize = resource.MustParse("10Gi")
+
+// SetDefaults writes the effective values of all optional RegistryCacheConfigSpec fields into the spec.
+// The values match the ones the registry cache extension falls back to, so the stored object shows exactly
+// what the cache runs with. Specs without an upstream are left untouched to keep their validation errors meaningful.
+func SetDefaults(spec *registrycache.RegistryCacheConfigSpec) {
+	if spec.Upstream == "" {
+		return
+	}
+
+	if spec.RemoteURL == nil {
+		spec.RemoteURL = ptr.To(registryutils.GetUpstreamURL(spec.Upstream))
+	}
+
+	if spec.Volume == nil {
+		spec.Volume = &registrycache.Volume{}
+	}
+	if spec.Volume.Size == nil {
+		spec.Volume.Size = ptr.To(DefaultVolumeSize.DeepCopy())
+	}
+
+	if spec.GarbageCollection == nil {
+		spec.GarbageCollection = &registrycache.GarbageCollection{
+			TTL: registrycacheext.DefaultTTL,
+		}
+	}
+
+	if spec.HTTP == nil {
+		spec.HTTP = &registrycache.HTTP{
+			TLS: true,
+		}
+	}
+}
+
+// VolumeSize returns the effective volume size of the spec, DefaultVolumeSize if spec.volume.size is not set.
+func VolumeSize(spec registrycache.RegistryCacheConfigSpec) resource.Quantity {
+	if spec.Volume == nil || spec.Volume.Size == nil {
+		return DefaultVolumeSize.DeepCopy()
+	}
+
+	return spec.Volume.Size.DeepCopy()
+}
@@ -0,0 +1,103 @@
+package defaults
+
+import (
+	"testing"
+	"time"
+
+	registrycache "github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestSetDefaults(t *testing.T) {
+	t.Run("all optional fields are defaulted", func(t *testing.T) {
+		spec := registrycache.RegistryCacheConfigSpec{
+			Upstream: "quay.io",
+		}
+
+		SetDefaults(&spec)
+
+		require.Equal(t, registrycache.RegistryCacheConfigSpec{
+			Upstream:  "quay.io",
+			RemoteURL: ptr.To("https://quay.io"),
+			Volume: &registrycache.Volume{
+				Size: ptr.To(resource.MustParse("10Gi")),
+			},
+			GarbageCollection: &registrycache.GarbageCollection{
+				TTL: metav1.Duration{Duration: 168 * time.Hour},
+			},
+			HTTP: &registrycache.HTTP{
+				TLS: true,
+			},
+		}, spec)
+	})
+
+	t.Run("docker.io remoteURL points to the registry endpoint", func(t *testing.T) {
+		spec := registrycache.RegistryCacheConfigSpec{
+			Upstream: "docker.io",
+		}
+
+		SetDefaults(&spec)
+
+		require.Equal(t, ptr.To("https://registry-1.docker.io"), spec.RemoteURL)
+	})
+
+	t.Run("explicit values are preserved", func(t *testing.T) {
+		spec := registrycache.RegistryCacheConfigSpec{
+			Upstream:  "my-registry.io:5000",
+			RemoteURL: ptr.To("http://my-registry.io:5000"),
+			Volume: &registrycache.Volume{
+				StorageClassName: ptr.To("premium"),
+			},
+			GarbageCollection: &registrycache.GarbageCollection{
+				TTL: metav1.Duration{Duration: 0},
+			},
+			HTTP: &registrycache.HTTP{
+				TLS: false,
+			},
+		}
+
+		SetDefaults(&spec)
+
+		require.Equal(t, registrycache.RegistryCacheConfigSpec{
+			Upstream:  "my-registry.io:5000",
+			RemoteURL: ptr.To("http://my-registry.io:5000"),
+			Volume: &registrycache.Volume{
+				Size:             ptr.To(resource.MustParse("10Gi")),
+				StorageClassName: ptr.To("premium"),
+			},
+			GarbageCollection: &registrycache.GarbageCollection{
+				TTL: metav1.Duration{Duration: 0},
+			},
+			HTTP: &registrycache.HTTP{
+				TLS: false,
+			},
+		}, spec)
+	})
+
+	t.Run("spec without upstream is not defaulted", func(t *testing.T) {
+		spec := registrycache.RegistryCacheConfigSpec{}
+
+		SetDefaults(&spec)
+
+		require.Equal(t, registrycache.RegistryCacheConfigSpec{}, spec)
+	})
+}
+
+func TestVolumeSize(t *testing.T) {
+	t.Run("default size", func(t *testing.T) {
+		size := VolumeSize(registrycache.RegistryCacheConfigSpec{Volume: &registrycache.Volume{}})
+
+		require.Equal(t, "10Gi", size.String())
+	})
+
+	t.Run("requested size", func(t *testing.T) {
+		size := VolumeSize(registrycache.RegistryCacheConfigSpec{
+			Volume: &registrycache.Volume{Size: ptr.To(resource.MustParse("20Gi"))},
+		})
+
+		require.Equal(t, "20Gi", size.String())
+	})
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kyma-project/registry-cache/internal/webhook/validations"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var secretlog = logf.Log.WithName("secret-resource")
+
+// SecretDeletionPolicy defines how the webhook reacts to the deletion of a Secret referenced by a RegistryCacheConfig.
+type SecretDeletionPolicy string
+
+const (
+	// SecretDeletionPolicyDeny rejects the deletion.
+	SecretDeletionPolicyDeny SecretDeletionPolicy = "deny"
+	// SecretDeletionPolicyWarn allows the deletion and returns a warning to the client.
+	SecretDeletionPolicyWarn SecretDeletionPolicy = "warn"
+)
+
+// ParseSecretDeletionPolicy returns the SecretDeletionPolicy for the given flag value.
+func ParseSecretDeletionPolicy(value string) (SecretDeletionPolicy, error) {
+	switch policy := SecretDeletionPolicy(value); policy {
+	case SecretDeletionPolicyDeny, SecretDeletionPolicyWarn:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unsupported secret deletion policy %q, must be one of: %s, %s", value, SecretDeletionPolicyDeny, SecretDeletionPolicyWarn)
+	}
+}
+
+// SetupSecretWebhookWithManager registers the webhook for Secrets in the manager.
+// The client must serve the field indexes registered by index.Setup, use the cached client of the manager.
+func SetupSecretWebhookWithManager(mgr ctrl.Manager, client client.Client, policy SecretDeletionPolicy) error {
+	return ctrl.NewWebhookManagedBy(mgr, &corev1.Secret{}).
+		WithValidator(NewSecretCustomValidator(client, policy)).
+		Complete()
+}
+
+// The failure policy is set to ignore, so that an unavailable module never blocks the deletion of Secrets in the cluster.
+// +kubebuilder:webhook:path=/validate--v1-secret,mutating=false,failurePolicy=ignore,sideEffects=None,groups="",resources=secrets,verbs=delete,versions=v1,name=vsecret-v1.kb.io,admissionReviewVersions=v1
+
+// SecretCustomValidator struct is responsible for protecting Secrets which are referenced by RegistryCacheConfig resources
+// from being deleted.
+type SecretCustomValidator struct {
+	client client.Client
+	policy SecretDeletionPolicy
+}
+
+func NewSecretCustomValidator(client client.Client, policy SecretDeletionPolicy) *SecretCustomValidator {
+	return &SecretCustomValidator{
+		client: client,
+		policy: policy,
+	}
+}
+
+var _ admission.Validator[*corev1.Secret] = &SecretCustomValidator{}
+
+// ValidateCreate implements admission.Validator so a webhook will be registered for the type Secret.
+func (v *SecretCustomValidator) ValidateCreate(_ context.Context, _ *corev1.Secret) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateUpdate implements admission.Validator so a webhook will be registered for the type Secret.
+func (v *SecretCustomValidator) ValidateUpdate(_ context.Context, _, _ *corev1.Secret) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateDelete implements admission.Validator so a webhook will be registered for the type Secret.
+func (v *SecretCustomValidator) ValidateDelete(ctx context.Context, secret *corev1.Secret) (admission.Warnings, error) {
+	errs := validations.ValidateSecretDeletion(ctx, v.client, secret)
+	if len(errs) == 0 {
+		return nil, nil
+	}
+
+	secretlog.Info("Deletion of a referenced Secret", "namespace", secret.GetNamespace(), "name", secret.GetName(), "policy", v.policy)
+
+	if v.policy == SecretDeletionPolicyWarn {
+		return admission.Warnings{errs.ToAggregate().Error()}, nil
+	}
+
+	return nil, errs.ToAggregate()
+}
@@ -0,0 +1,90 @@
+package v1
+
+import (
+	"context"
+	"testing"
+
+	registrycache "github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/index"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParseSecretDeletionPolicy(t *testing.T) {
+	for _, value := range []string{"deny", "warn"} {
+		policy, err := ParseSecretDeletionPolicy(value)
+		require.NoError(t, err)
+		require.Equal(t, SecretDeletionPolicy(value), policy)
+	}
+
+	_, err := ParseSecretDeletionPolicy("ignore")
+	require.Error(t, err)
+}
+
+func TestValidateDelete(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "upstream-secret",
+			Namespace: "default",
+		},
+	}
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+	config := &registrycache.RegistryCacheConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "quay",
+			Namespace: "default",
+		},
+		Spec: registrycache.RegistryCacheConfigSpec{
+			Upstream:            "quay.io",
+			SecretReferenceName: ptr.To("upstream-secret"),
+		},
+	}
+
+	t.Run("deny policy rejects the deletion of a referenced secret", func(t *testing.T) {
+		validator := NewSecretCustomValidator(fixFakeClient(namespace, config), SecretDeletionPolicyDeny)
+
+		warnings, err := validator.ValidateDelete(context.Background(), secret)
+
+		require.Empty(t, warnings)
+		require.ErrorContains(t, err, "secret upstream-secret is referenced by RegistryCacheConfig quay")
+	})
+
+	t.Run("warn policy allows the deletion of a referenced secret with a warning", func(t *testing.T) {
+		validator := NewSecretCustomValidator(fixFakeClient(namespace, config), SecretDeletionPolicyWarn)
+
+		warnings, err := validator.ValidateDelete(context.Background(), secret)
+
+		require.NoError(t, err)
+		require.Len(t, warnings, 1)
+		require.Contains(t, warnings[0], "secret upstream-secret is referenced by RegistryCacheConfig quay")
+	})
+
+	t.Run("deletion of an unreferenced secret is allowed", func(t *testing.T) {
+		validator := NewSecretCustomValidator(fixFakeClient(namespace), SecretDeletionPolicyDeny)
+
+		warnings, err := validator.ValidateDelete(context.Background(), secret)
+
+		require.NoError(t, err)
+		require.Empty(t, warnings)
+	})
+}
+
+func fixFakeClient(initObjs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	_ = registrycache.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjs...).
+		WithIndex(&registrycache.RegistryCacheConfig{}, index.RegistryCacheConfigSecretReferenceName, index.SecretReferenceName).
+		WithIndex(&registrycache.RegistryCacheConfig{}, index.RegistryCacheConfigUpstream, index.Upstream).
+		Build()
+}
@@ -18,8 +18,11 @@ package v1beta1
 
 import (
 	"context"
-	"fmt"
+	"github.com/kyma-project/registry-cache/internal/webhook/defaults"
 	"github.com/kyma-project/registry-cache/internal/webhook/validations"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -34,12 +37,62 @@ import (
 var registrycacheconfiglog = logf.Log.WithName("registrycacheconfig-resource")
 
 // SetupRegistryCacheConfigWebhookWithManager registers the webhook for RegistryCacheConfig in the manager.
-func SetupRegistryCacheConfigWebhookWithManager(mgr ctrl.Manager, client client.Client) error {
+// The client must serve the field indexes registered by index.Setup, use the cached client of the manager.
+func SetupRegistryCacheConfigWebhookWithManager(mgr ctrl.Manager, client client.Client, dnsValidator validations.DNSValidator, opts ...validations.Option) error {
 	return ctrl.NewWebhookManagedBy(mgr, &corekymaprojectiov1beta1.RegistryCacheConfig{}).
-		WithValidator(NewRegistryCacheConfigCustomValidator(client)).
+		WithDefaulter(&RegistryCacheConfigCustomDefaulter{client: client}).
+		WithValidator(NewRegistryCacheConfigCustomValidator(client, dnsValidator, opts...)).
 		Complete()
 }
 
+// +kubebuilder:webhook:path=/mutate-core-kyma-project-io-v1beta1-registrycacheconfig,mutating=true,failurePolicy=fail,sideEffects=None,groups=core.kyma-project.io,resources=registrycacheconfigs,verbs=create;update,versions=v1beta1,name=mregistrycacheconfig-v1beta1.kb.io,admissionReviewVersions=v1
+
+// RegistryCacheConfigCustomDefaulter struct is responsible for setting default values on the RegistryCacheConfig resource
+// when it is created or updated.
+//
+// NOTE: The +kubebuilder:object:generate=false marker prevents controller-gen from generating DeepCopy methods,
+// as it is used only for temporary operations and does not need to be deeply copied.
+type RegistryCacheConfigCustomDefaulter struct {
+	client client.Client
+}
+
+var _ admission.Defaulter[*corekymaprojectiov1beta1.RegistryCacheConfig] = &RegistryCacheConfigCustomDefaulter{}
+
+// Default implements admission.Defaulter so a webhook will be registered for the type RegistryCacheConfig.
+func (d *RegistryCacheConfigCustomDefaulter) Default(ctx context.Context, registrycacheconfig *corekymaprojectiov1beta1.RegistryCacheConfig) error {
+	registrycacheconfiglog.Info("Defaulting for RegistryCacheConfig", "name", registrycacheconfig.GetName())
+
+	defaults.SetDefaults(&registrycacheconfig.Spec)
+
+	// the storage class is immutable, so the default StorageClass is only filled in on creation
+	if req, err := admission.RequestFromContext(ctx); err == nil && req.Operation == admissionv1.Create {
+		return d.setDefaultStorageClass(ctx, registrycacheconfig)
+	}
+
+	return nil
+}
+
+// setDefaultStorageClass sets spec.volume.storageClassName to the default StorageClass of the cluster if it is not set,
+// so that the stored object names the StorageClass of the volume. Without a default StorageClass the name stays unset.
+// The annotation records the filled in name for the warning of the validation.
+func (d *RegistryCacheConfigCustomDefaulter) setDefaultStorageClass(ctx context.Context, registrycacheconfig *corekymaprojectiov1beta1.RegistryCacheConfig) error {
+	volume := registrycacheconfig.Spec.Volume
+	if volume == nil || volume.StorageClassName != nil {
+		return nil
+	}
+
+	storageClass, err := validations.DefaultStorageClass(ctx, d.client)
+	if err != nil {
+		return err
+	}
+	if storageClass != nil {
+		volume.StorageClassName = ptr.To(storageClass.Name)
+		metav1.SetMetaDataAnnotation(&registrycacheconfig.ObjectMeta, validations.AnnotationDefaultedStorageClass, storageClass.Name)
+	}
+
+	return nil
+}
+
 // TODO(user): change verbs to "verbs=create;update;delete" if you want to enable deletion validations.
 // NOTE: The 'path' attribute must follow a specific pattern and should not be modified directly here.
 // Modifying the path for an invalid path can cause API server errors; failing to locate the webhook.
@@ -51,39 +104,35 @@ func SetupRegistryCacheConfigWebhookWithManager(mgr ctrl.Manager, client client.
 // NOTE: The +kubebuilder:object:generate=false marker prevents controller-gen from generating DeepCopy methods,
 // as this struct is used only for temporary operations and does not need to be deeply copied.
 type RegistryCacheConfigCustomValidator struct {
-	client client.Client
+	client       client.Client
+	dnsValidator validations.DNSValidator
+	opts         []validations.Option
 }
 
-func NewRegistryCacheConfigCustomValidator(client client.Client) *RegistryCacheConfigCustomValidator {
+func NewRegistryCacheConfigCustomValidator(client client.Client, dnsValidator validations.DNSValidator, opts ...validations.Option) *RegistryCacheConfigCustomValidator {
 	return &RegistryCacheConfigCustomValidator{
-		client: client,
+		client:       client,
+		dnsValidator: dnsValidator,
+		opts:         opts,
 	}
 }
 
 var _ admission.Validator[*corekymaprojectiov1beta1.RegistryCacheConfig] = &RegistryCacheConfigCustomValidator{}
 
 // ValidateCreate implements admission.Validator so a webhook will be registered for the type RegistryCacheConfig.
-func (v *RegistryCacheConfigCustomValidator) ValidateCreate(_ context.Context, registrycacheconfig *corekymaprojectiov1beta1.RegistryCacheConfig) (admission.Warnings, error) {
+func (v *RegistryCacheConfigCustomValidator) ValidateCreate(ctx context.Context, registrycacheconfig *corekymaprojectiov1beta1.RegistryCacheConfig) (admission.Warnings, error) {
 	registrycacheconfiglog.Info("Validation for RegistryCacheConfig upon creation", "name", registrycacheconfig.GetName())
 
-	var registrycacheconfigs corekymaprojectiov1beta1.RegistryCacheConfigList
-	err := v.client.List(context.Background(), &registrycacheconfigs, client.InNamespace(registrycacheconfig.Namespace))
-	if err != nil {
-		return nil, fmt.Errorf("failed to list existing RegistryCacheConfig resources: %w", err)
-	}
+	dnsWarnings, errs := validations.NewValidator(v.dnsValidator, v.client, v.opts...).Do(ctx, registrycacheconfig)
 
-	return nil, validations.NewValidator(validations.DefaultDNSValidator{}, v.client).Do(registrycacheconfig).ToAggregate()
+	return append(validations.Warnings(registrycacheconfig, nil), dnsWarnings...), errs.ToAggregate()
 }
 
 // ValidateUpdate implements admission.Validator so a webhook will be registered for the type RegistryCacheConfig.
-func (v *RegistryCacheConfigCustomValidator) ValidateUpdate(_ context.Context, oldRegistryCacheConfig, newRegistryCacheConfig *corekymaprojectiov1beta1.RegistryCacheConfig) (admission.Warnings, error) {
-	var registrycacheconfigs corekymaprojectiov1beta1.RegistryCacheConfigList
-	err := v.client.List(context.Background(), &registrycacheconfigs, client.InNamespace(newRegistryCacheConfig.Namespace))
-	if err != nil {
-		return nil, fmt.Errorf("failed to list existing RegistryCacheConfig resources: %w", err)
-	}
+func (v *RegistryCacheConfigCustomValidator) ValidateUpdate(ctx context.Context, oldRegistryCacheConfig, newRegistryCacheConfig *corekymaprojectiov1beta1.RegistryCacheConfig) (admission.Warnings, error) {
+	dnsWarnings, errs := validations.NewValidator(v.dnsValidator, v.client, v.opts...).DoOnUpdate(ctx, newRegistryCacheConfig, oldRegistryCacheConfig)
 
-	return nil, validations.NewValidator(validations.DefaultDNSValidator{}, v.client).DoOnUpdate(newRegistryCacheConfig, oldRegistryCacheConfig).ToAggregate()
+	return append(validations.Warnings(newRegistryCacheConfig, oldRegistryCacheConfig), dnsWarnings...), errs.ToAggregate()
 }
 
 // ValidateDelete implements admission.Validator so a webhook will be registered for the type RegistryCacheConfig.
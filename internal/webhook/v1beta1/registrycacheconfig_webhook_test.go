@@ -17,11 +17,20 @@ limitations under the License.
 package v1beta1
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	corekymaprojectiov1beta1 "github.com/kyma-project/registry-cache/api/v1beta1"
-	// TODO (user): Add any additional imports if needed
+	"github.com/kyma-project/registry-cache/internal/webhook/validations"
 )
 
 var _ = Describe("RegistryCacheConfig Webhook", func() {
@@ -29,6 +38,7 @@ var _ = Describe("RegistryCacheConfig Webhook", func() {
 		obj       *corekymaprojectiov1beta1.RegistryCacheConfig
 		oldObj    *corekymaprojectiov1beta1.RegistryCacheConfig
 		validator RegistryCacheConfigCustomValidator
+		defaulter RegistryCacheConfigCustomDefaulter
 	)
 
 	BeforeEach(func() {
@@ -36,6 +46,8 @@ var _ = Describe("RegistryCacheConfig Webhook", func() {
 		oldObj = &corekymaprojectiov1beta1.RegistryCacheConfig{}
 		validator = RegistryCacheConfigCustomValidator{}
 		Expect(validator).NotTo(BeNil(), "Expected validator to be initialized")
+		defaulter = RegistryCacheConfigCustomDefaulter{}
+		Expect(defaulter).NotTo(BeNil(), "Expected defaulter to be initialized")
 		Expect(oldObj).NotTo(BeNil(), "Expected oldObj to be initialized")
 		Expect(obj).NotTo(BeNil(), "Expected obj to be initialized")
 		// TODO (user): Add any setup logic common to all tests
@@ -46,16 +58,60 @@ var _ = Describe("RegistryCacheConfig Webhook", func() {
 	})
 
 	Context("When creating RegistryCacheConfig under Defaulting Webhook", func() {
-		// TODO (user): Add logic for defaulting webhooks
-		// Example:
-		// It("Should apply defaults when a required field is empty", func() {
-		//     By("simulating a scenario where defaults should be applied")
-		//     obj.SomeFieldWithDefault = ""
-		//     By("calling the Default method to apply defaults")
-		//     defaulter.Default(ctx, obj)
-		//     By("checking that the default values are set")
-		//     Expect(obj.SomeFieldWithDefault).To(Equal("default_value"))
-		// })
+		It("Should apply defaults when optional fields are empty", func() {
+			By("simulating a scenario where defaults should be applied")
+			obj.Spec.Upstream = "quay.io"
+			By("calling the Default method to apply defaults")
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			By("checking that the default values are set")
+			Expect(obj.Spec.RemoteURL).To(Equal(ptr.To("https://quay.io")))
+			Expect(obj.Spec.Volume.Size.Equal(resource.MustParse("10Gi"))).To(BeTrue())
+			Expect(obj.Spec.GarbageCollection.TTL).To(Equal(metav1.Duration{Duration: 168 * time.Hour}))
+			Expect(obj.Spec.HTTP.TLS).To(BeTrue())
+		})
+
+		It("Should not override values set by the user", func() {
+			By("simulating a scenario where all optional fields are set")
+			obj.Spec.Upstream = "quay.io"
+			obj.Spec.Volume = &corekymaprojectiov1beta1.Volume{Size: ptr.To(resource.MustParse("50Gi"))}
+			obj.Spec.GarbageCollection = &corekymaprojectiov1beta1.GarbageCollection{TTL: metav1.Duration{}}
+			obj.Spec.HTTP = &corekymaprojectiov1beta1.HTTP{TLS: false}
+			By("calling the Default method to apply defaults")
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			By("checking that the user values are preserved")
+			Expect(obj.Spec.Volume.Size.Equal(resource.MustParse("50Gi"))).To(BeTrue())
+			Expect(obj.Spec.GarbageCollection.TTL).To(Equal(metav1.Duration{}))
+			Expect(obj.Spec.HTTP.TLS).To(BeFalse())
+		})
+	})
+
+	Context("When creating RegistryCacheConfig with no storage class under Defaulting Webhook", func() {
+		defaultClass := &storagev1.StorageClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "default",
+				Annotations: map[string]string{"storageclass.kubernetes.io/is-default-class": "true"},
+			},
+			Provisioner: "disk.csi.example.com",
+		}
+
+		It("Should set the default storage class on creation", func() {
+			defaulter = RegistryCacheConfigCustomDefaulter{client: fake.NewClientBuilder().WithObjects(defaultClass).Build()}
+			obj.Spec.Upstream = "quay.io"
+			createCtx := admission.NewContextWithRequest(ctx, admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Operation: admissionv1.Create}})
+
+			Expect(defaulter.Default(createCtx, obj)).To(Succeed())
+			Expect(obj.Spec.Volume.StorageClassName).To(Equal(ptr.To("default")))
+			Expect(obj.Annotations).To(HaveKeyWithValue(validations.AnnotationDefaultedStorageClass, "default"))
+		})
+
+		It("Should not set the storage class on update", func() {
+			defaulter = RegistryCacheConfigCustomDefaulter{client: fake.NewClientBuilder().WithObjects(defaultClass).Build()}
+			obj.Spec.Upstream = "quay.io"
+			updateCtx := admission.NewContextWithRequest(ctx, admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Operation: admissionv1.Update}})
+
+			Expect(defaulter.Default(updateCtx, obj)).To(Succeed())
+			Expect(obj.Spec.Volume.StorageClassName).To(BeNil())
+		})
 	})
 
 	Context("When creating or updating RegistryCacheConfig under Validating Webhook", func() {
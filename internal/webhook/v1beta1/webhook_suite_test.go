@@ -40,6 +40,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	corekymaprojectiov1beta1 "github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/index"
+	"github.com/kyma-project/registry-cache/internal/webhook/validations"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -109,7 +111,10 @@ var _ = BeforeSuite(func() {
 	})
 	Expect(err).NotTo(HaveOccurred())
 
-	err = SetupRegistryCacheConfigWebhookWithManager(mgr, nil)
+	err = index.Setup(ctx, mgr.GetFieldIndexer())
+	Expect(err).NotTo(HaveOccurred())
+
+	err = SetupRegistryCacheConfigWebhookWithManager(mgr, mgr.GetClient(), validations.NewDNSValidator(validations.DNSValidatorOptions{}))
 	Expect(err).NotTo(HaveOccurred())
 
 	// +kubebuilder:scaffold:webhook
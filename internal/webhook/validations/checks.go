@@ -0,0 +1,92 @@
+package validations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// DefaultTimeout is the time budget of all checks of a single validation if the context carries no deadline.
+// It is below the default admission webhook timeout of 10 seconds, so that the webhook always answers in time.
+const DefaultTimeout = 8 * time.Second
+
+// check is a validation which depends on external state, such as the cluster or DNS.
+type check struct {
+	// fldPath is the field reported when the check does not complete in time.
+	fldPath *field.Path
+	// description names the check in the timeout error.
+	description string
+	run         func(ctx context.Context) field.ErrorList
+}
+
+type checkResult struct {
+	index    int
+	errs     field.ErrorList
+	timedOut bool
+}
+
+// runChecks runs independent checks concurrently under a common deadline. The deadline of the context is used
+// if set, otherwise the timeout is applied. Checks which do not complete in time, or which fail after the
+// deadline passed, are reported as timeout errors on their field instead of the errors caused by the cancellation.
+func runChecks(ctx context.Context, timeout time.Duration, checks ...check) field.ErrorList {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	results := make(chan checkResult, len(checks))
+	for i, c := range checks {
+		go func() {
+			errs := c.run(ctx)
+			results <- checkResult{index: i, errs: errs, timedOut: ctx.Err() != nil}
+		}()
+	}
+
+	checkErrs := make([]field.ErrorList, len(checks))
+	completed := make([]bool, len(checks))
+	record := func(result checkResult) {
+		completed[result.index] = true
+		if len(result.errs) > 0 && result.timedOut {
+			checkErrs[result.index] = field.ErrorList{timeoutError(checks[result.index])}
+			return
+		}
+		checkErrs[result.index] = result.errs
+	}
+
+	for pending := len(checks); pending > 0 && ctx.Err() == nil; {
+		select {
+		case result := <-results:
+			record(result)
+			pending--
+		case <-ctx.Done():
+		}
+	}
+
+	// keep the results of checks which completed together with the deadline
+	for drained := false; !drained; {
+		select {
+		case result := <-results:
+			record(result)
+		default:
+			drained = true
+		}
+	}
+
+	var allErrs field.ErrorList
+	for i, c := range checks {
+		if !completed[i] {
+			allErrs = append(allErrs, timeoutError(c))
+			continue
+		}
+		allErrs = append(allErrs, checkErrs[i]...)
+	}
+
+	return allErrs
+}
+
+func timeoutError(c check) *field.Error {
+	return field.InternalError(c.fldPath, fmt.Errorf("%s did not complete before the validation deadline, try again later", c.description))
+}
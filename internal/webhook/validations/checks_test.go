@@ -0,0 +1,116 @@
+package validations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	registrycache "github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/webhook/validations/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+)
+
+func TestRunChecks(t *testing.T) {
+	upstreamPath := field.NewPath("spec").Child("upstream")
+	remoteURLPath := field.NewPath("spec").Child("remoteURL")
+
+	sleepingCheck := func(fldPath *field.Path, d time.Duration, errs field.ErrorList) check {
+		return check{
+			fldPath:     fldPath,
+			description: "sleeping check",
+			run: func(ctx context.Context) field.ErrorList {
+				select {
+				case <-time.After(d):
+					return errs
+				case <-ctx.Done():
+					return field.ErrorList{field.InternalError(fldPath, ctx.Err())}
+				}
+			},
+		}
+	}
+
+	t.Run("checks run concurrently", func(t *testing.T) {
+		start := time.Now()
+
+		errs := runChecks(context.Background(), time.Second,
+			sleepingCheck(upstreamPath, 300*time.Millisecond, nil),
+			sleepingCheck(remoteURLPath, 300*time.Millisecond, field.ErrorList{field.Invalid(remoteURLPath, "url", "invalid")}),
+			sleepingCheck(upstreamPath, 300*time.Millisecond, nil),
+		)
+
+		require.Less(t, time.Since(start), 800*time.Millisecond)
+		validateResult(t, field.ErrorList{field.Invalid(remoteURLPath, "url", "invalid")}, errs)
+	})
+
+	t.Run("checks exceeding the timeout are reported", func(t *testing.T) {
+		errs := runChecks(context.Background(), 50*time.Millisecond,
+			sleepingCheck(upstreamPath, 0, nil),
+			sleepingCheck(remoteURLPath, time.Minute, nil),
+		)
+
+		validateTimeoutErrors(t, []*field.Path{remoteURLPath}, errs)
+		require.Contains(t, errs[0].Detail, "sleeping check did not complete before the validation deadline")
+	})
+
+	t.Run("checks ignoring the deadline do not block", func(t *testing.T) {
+		blocked := make(chan struct{})
+		defer close(blocked)
+
+		start := time.Now()
+		errs := runChecks(context.Background(), 50*time.Millisecond, check{
+			fldPath:     upstreamPath,
+			description: "blocking check",
+			run: func(_ context.Context) field.ErrorList {
+				<-blocked
+				return nil
+			},
+		})
+
+		require.Less(t, time.Since(start), 5*time.Second)
+		validateTimeoutErrors(t, []*field.Path{upstreamPath}, errs)
+	})
+
+	t.Run("deadline of the context takes precedence", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		errs := runChecks(ctx, time.Minute, sleepingCheck(upstreamPath, time.Minute, nil))
+
+		validateTimeoutErrors(t, []*field.Path{upstreamPath}, errs)
+	})
+}
+
+func TestValidatorTimeout(t *testing.T) {
+	slowResolver := &mocks.DNSValidator{}
+	slowResolver.On("IsResolvable", mock.Anything, mock.Anything).Return(func(ctx context.Context, _ string) bool {
+		<-ctx.Done()
+		return false
+	})
+
+	cfg := buildConfig("config1", "default", registrycache.RegistryCacheConfigSpec{
+		Upstream:  "docker.io",
+		RemoteURL: ptr.To("https://registry-1.docker.io"),
+	})
+
+	start := time.Now()
+	_, errs := NewValidator(slowResolver, fixFakeClient(), WithTimeout(100*time.Millisecond)).Do(context.Background(), &cfg)
+
+	require.Less(t, time.Since(start), 5*time.Second)
+	validateTimeoutErrors(t, []*field.Path{
+		field.NewPath("spec").Child("upstream"),
+		field.NewPath("spec").Child("remoteURL"),
+	}, errs)
+}
+
+func validateTimeoutErrors(t *testing.T, expectedPaths []*field.Path, actualErrors field.ErrorList) {
+	require.Len(t, actualErrors, len(expectedPaths))
+
+	for i, expectedPath := range expectedPaths {
+		require.Equal(t, field.ErrorTypeInternal, actualErrors[i].Type)
+		require.Equal(t, expectedPath.String(), actualErrors[i].Field)
+		require.Contains(t, actualErrors[i].Detail, "did not complete before the validation deadline")
+	}
+}
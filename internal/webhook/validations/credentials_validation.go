@@ -0,0 +1,74 @@
+package validations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	registryutils "github.com/gardener/gardener-extension-registry-cache/pkg/utils/registry"
+	registrycache "github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/credentials"
+	"github.com/kyma-project/registry-cache/internal/distribution"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CredentialsVerifier verifies credentials against the OCI distribution API of a registry.
+type CredentialsVerifier interface {
+	// Ping returns distribution.ErrUnauthorized if the registry rejects the credentials.
+	Ping(ctx context.Context, registryURL string, proxy *distribution.Proxy, credentials *distribution.Credentials) error
+}
+
+// validateCredentials performs the /v2/ handshake against the remote URL with the credentials of the referenced Secret,
+// through the proxy of spec.proxy, as the registry cache would. Only rejected credentials are reported, an unreachable registry does not fail the validation.
+func validateCredentials(ctx context.Context, newConfig *registrycache.RegistryCacheConfig, runtimeClient client.Client, verifier CredentialsVerifier) field.ErrorList {
+	if verifier == nil || newConfig.Spec.SecretReferenceName == nil || newConfig.Spec.Upstream == "" {
+		return nil
+	}
+
+	var registryCacheSecret v1.Secret
+	if err := runtimeClient.Get(ctx, types.NamespacedName{
+		Name:      *newConfig.Spec.SecretReferenceName,
+		Namespace: newConfig.Namespace,
+	}, &registryCacheSecret); err != nil {
+		return nil
+	}
+
+	registryURL := registryutils.GetUpstreamURL(newConfig.Spec.Upstream)
+	if newConfig.Spec.RemoteURL != nil {
+		registryURL = *newConfig.Spec.RemoteURL
+	}
+
+	// bound the whole handshake, so that it finishes within the admission webhook timeout
+	ctx, cancel := context.WithTimeout(ctx, distribution.DefaultTimeout)
+	defer cancel()
+
+	creds, err := credentials.Extract(&registryCacheSecret, newConfig.Spec.Upstream)
+	if err != nil {
+		return nil
+	}
+
+	err = verifier.Ping(ctx, registryURL, proxyOf(newConfig), &creds)
+	if errors.Is(err, distribution.ErrUnauthorized) {
+		return field.ErrorList{field.Invalid(field.NewPath("spec").Child("secretReferenceName"), *newConfig.Spec.SecretReferenceName,
+			fmt.Sprintf("the upstream registry %s rejected the credentials of secret %s", registryURL, *newConfig.Spec.SecretReferenceName))}
+	}
+
+	return nil
+}
+
+// proxyOf returns the proxies of spec.proxy, or nil if the config sets none.
+func proxyOf(config *registrycache.RegistryCacheConfig) *distribution.Proxy {
+	proxy := config.Spec.Proxy
+	if proxy == nil || (proxy.HTTPProxy == nil && proxy.HTTPSProxy == nil) {
+		return nil
+	}
+
+	return &distribution.Proxy{
+		HTTPProxy:  ptr.Deref(proxy.HTTPProxy, ""),
+		HTTPSProxy: ptr.Deref(proxy.HTTPSProxy, ""),
+	}
+}
@@ -0,0 +1,118 @@
+package validations
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	registrycache "github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/distribution"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+)
+
+func TestValidateCredentials(t *testing.T) {
+	testEnv := newTestEnv()
+
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); ok && username == "user" && password == "password" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer registry.Close()
+
+	wrongCredentialsSecret := buildSecret("wrong-credentials", "default", true, map[string][]byte{
+		"username": []byte("user"),
+		"password": []byte("wrong"),
+	})
+	dockerConfigSecret := buildSecret("pull-secret", "default", false, map[string][]byte{
+		v1.DockerConfigJsonKey: []byte(`{"auths": {"` + registry.URL + `": {"username": "user", "password": "wrong"}}}`),
+	})
+	dockerConfigSecret.Type = v1.SecretTypeDockerConfigJson
+	fakeClient := fixFakeClient(&testEnv.validSecret, &wrongCredentialsSecret, &dockerConfigSecret)
+	validator := NewValidator(testEnv.dnsResolverAllOK, fakeClient, WithCredentialsVerifier(distribution.NewClient(nil)))
+
+	t.Run("accepted credentials", func(t *testing.T) {
+		config := buildConfig("config", "default", registrycache.RegistryCacheConfigSpec{
+			Upstream:            strings.TrimPrefix(registry.URL, "http://"),
+			RemoteURL:           ptr.To(registry.URL),
+			SecretReferenceName: ptr.To(testEnv.validSecret.Name),
+		})
+
+		_, errs := validator.Do(context.Background(), &config)
+		require.Empty(t, errs)
+	})
+
+	t.Run("rejected credentials", func(t *testing.T) {
+		config := buildConfig("config", "default", registrycache.RegistryCacheConfigSpec{
+			Upstream:            strings.TrimPrefix(registry.URL, "http://"),
+			RemoteURL:           ptr.To(registry.URL),
+			SecretReferenceName: ptr.To(wrongCredentialsSecret.Name),
+		})
+
+		_, errs := validator.Do(context.Background(), &config)
+		validateResult(t, field.ErrorList{
+			field.Invalid(field.NewPath("spec").Child("secretReferenceName"), wrongCredentialsSecret.Name, "rejected the credentials of secret wrong-credentials"),
+		}, errs)
+	})
+
+	t.Run("rejected credentials of a dockerconfigjson secret", func(t *testing.T) {
+		config := buildConfig("config", "default", registrycache.RegistryCacheConfigSpec{
+			Upstream:            strings.TrimPrefix(registry.URL, "http://"),
+			RemoteURL:           ptr.To(registry.URL),
+			SecretReferenceName: ptr.To(dockerConfigSecret.Name),
+		})
+
+		_, errs := validator.Do(context.Background(), &config)
+		validateResult(t, field.ErrorList{
+			field.Invalid(field.NewPath("spec").Child("secretReferenceName"), dockerConfigSecret.Name, "rejected the credentials of secret pull-secret"),
+		}, errs)
+	})
+
+	t.Run("rejected credentials through the proxy", func(t *testing.T) {
+		// the test registry also serves the requests sent to it as a proxy
+		config := buildConfig("config", "default", registrycache.RegistryCacheConfigSpec{
+			Upstream:            "registry.example.com",
+			RemoteURL:           ptr.To("http://registry.example.com"),
+			SecretReferenceName: ptr.To(wrongCredentialsSecret.Name),
+			Proxy:               &registrycache.Proxy{HTTPProxy: ptr.To(registry.URL)},
+		})
+
+		_, errs := validator.Do(context.Background(), &config)
+		validateResult(t, field.ErrorList{
+			field.Invalid(field.NewPath("spec").Child("secretReferenceName"), wrongCredentialsSecret.Name, "rejected the credentials of secret wrong-credentials"),
+		}, errs)
+	})
+
+	t.Run("credentials are not verified without the option", func(t *testing.T) {
+		config := buildConfig("config", "default", registrycache.RegistryCacheConfigSpec{
+			Upstream:            strings.TrimPrefix(registry.URL, "http://"),
+			RemoteURL:           ptr.To(registry.URL),
+			SecretReferenceName: ptr.To(wrongCredentialsSecret.Name),
+		})
+
+		_, errs := NewValidator(testEnv.dnsResolverAllOK, fakeClient).Do(context.Background(), &config)
+		require.Empty(t, errs)
+	})
+
+	t.Run("unreachable registry is not reported", func(t *testing.T) {
+		unreachable := httptest.NewServer(http.NotFoundHandler())
+		unreachable.Close()
+
+		config := buildConfig("config", "default", registrycache.RegistryCacheConfigSpec{
+			Upstream:            strings.TrimPrefix(unreachable.URL, "http://"),
+			RemoteURL:           ptr.To(unreachable.URL),
+			SecretReferenceName: ptr.To(wrongCredentialsSecret.Name),
+		})
+
+		_, errs := validator.Do(context.Background(), &config)
+		require.Empty(t, errs)
+	})
+}
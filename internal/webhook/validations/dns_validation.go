@@ -2,7 +2,13 @@ package validations
 
 import (
 	"context"
+	"fmt"
 	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -11,22 +17,223 @@ import (
 //go:generate mockery --name=DNSValidator
 type DNSValidator interface {
 	// IsResolvable returns true if the given host has at least one A/AAAA record.
-	IsResolvable(host string) bool
+	// The lookup is aborted when the context is done.
+	IsResolvable(ctx context.Context, host string) bool
 }
 
-// DefaultDNSValidator implements DNSValidator using the net.Resolver to check host resolvability.
-type DefaultDNSValidator struct{}
+// DNSMode defines how the validator reacts to hosts which are not DNS resolvable.
+type DNSMode string
 
-func (r DefaultDNSValidator) IsResolvable(host string) bool {
+const (
+	// DNSModeStrict rejects RegistryCacheConfigs with hosts which are not DNS resolvable.
+	DNSModeStrict DNSMode = "strict"
+	// DNSModeWarn accepts RegistryCacheConfigs with hosts which are not DNS resolvable and returns a warning.
+	DNSModeWarn DNSMode = "warn"
+	// DNSModeOff skips the DNS checks, for example, in air-gapped clusters.
+	DNSModeOff DNSMode = "off"
+)
+
+// ParseDNSMode returns the DNSMode for the given flag value.
+func ParseDNSMode(value string) (DNSMode, error) {
+	switch mode := DNSMode(value); mode {
+	case DNSModeStrict, DNSModeWarn, DNSModeOff:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unsupported DNS mode %q, must be one of: %s, %s, %s", value, DNSModeStrict, DNSModeWarn, DNSModeOff)
+	}
+}
+
+// ParseNameservers returns the nameservers of a comma-separated list in the `<ip>[:<port>]` format.
+// The entries are trimmed and empty entries are skipped.
+func ParseNameservers(value string) ([]string, error) {
+	var nameservers []string
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		host, port, err := net.SplitHostPort(entry)
+		if err != nil {
+			// an IPv6 address without a port has no brackets
+			host, port = entry, ""
+		}
+		if _, err := netip.ParseAddr(host); err != nil {
+			return nil, fmt.Errorf("invalid nameserver %q, must be in the <ip>[:<port>] format: %w", entry, err)
+		}
+		if port != "" {
+			if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+				return nil, fmt.Errorf("invalid port of nameserver %q: %w", entry, err)
+			}
+		}
+		nameservers = append(nameservers, entry)
+	}
+
+	return nameservers, nil
+}
+
+const (
+	// DefaultDNSTimeout is the timeout of a single DNS lookup.
+	DefaultDNSTimeout = 2 * time.Second
+	// DefaultDNSPositiveTTL is the time a resolvable host is cached.
+	DefaultDNSPositiveTTL = 5 * time.Minute
+	// DefaultDNSNegativeTTL is the time a host which is not resolvable is cached.
+	DefaultDNSNegativeTTL = 30 * time.Second
+
+	// maxDNSCacheEntries bounds the cache, so that requests with ever new hosts cannot grow it without limit.
+	maxDNSCacheEntries = 1024
+)
+
+// DNSValidatorOptions configures the ResolverDNSValidator.
+type DNSValidatorOptions struct {
+	// Nameservers in the `<ip>[:<port>]` format, for example, the IP of the kube-dns Service.
+	// The resolver of the system is used if empty.
+	Nameservers []string
+	// Timeout of a single lookup, DefaultDNSTimeout is used if not set.
+	Timeout time.Duration
+	// PositiveTTL is the time a resolvable host is cached, 0 disables the caching.
+	PositiveTTL time.Duration
+	// NegativeTTL is the time a host which is not resolvable is cached, 0 disables the caching.
+	NegativeTTL time.Duration
+}
+
+// ResolverDNSValidator implements DNSValidator using a net.Resolver with optional custom nameservers
+// and caching of the lookup results.
+type ResolverDNSValidator struct {
+	resolver    *net.Resolver
+	timeout     time.Duration
+	positiveTTL time.Duration
+	negativeTTL time.Duration
+	now         func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]dnsCacheEntry
+}
+
+type dnsCacheEntry struct {
+	resolvable bool
+	expiresAt  time.Time
+}
+
+// NewDNSValidator constructs a ResolverDNSValidator.
+func NewDNSValidator(opts DNSValidatorOptions) *ResolverDNSValidator {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultDNSTimeout
+	}
+
+	return &ResolverDNSValidator{
+		resolver:    newResolver(opts.Nameservers),
+		timeout:     opts.Timeout,
+		positiveTTL: opts.PositiveTTL,
+		negativeTTL: opts.NegativeTTL,
+		now:         time.Now,
+		cache:       map[string]dnsCacheEntry{},
+	}
+}
+
+func (r *ResolverDNSValidator) IsResolvable(ctx context.Context, host string) bool {
 	if host == "" {
 		return false
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	if resolvable, ok := r.cached(host); ok {
+		return resolvable
+	}
+
+	lookupCtx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	if addrs, err := net.DefaultResolver.LookupHost(ctx, host); err == nil && len(addrs) > 0 {
-		return true
+	addrs, err := r.resolver.LookupHost(lookupCtx, host)
+	resolvable := err == nil && len(addrs) > 0
+
+	// a lookup aborted by the caller says nothing about the host
+	if ctx.Err() == nil {
+		r.store(host, resolvable)
+	}
+
+	return resolvable
+}
+
+func (r *ResolverDNSValidator) cached(host string) (bool, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.cache[host]
+	if !ok {
+		return false, false
+	}
+	if !r.now().Before(entry.expiresAt) {
+		delete(r.cache, host)
+		return false, false
+	}
+
+	return entry.resolvable, true
+}
+
+func (r *ResolverDNSValidator) store(host string, resolvable bool) {
+	ttl := r.negativeTTL
+	if resolvable {
+		ttl = r.positiveTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.cache[host]; !ok && len(r.cache) >= maxDNSCacheEntries {
+		r.evict()
+	}
+
+	r.cache[host] = dnsCacheEntry{
+		resolvable: resolvable,
+		expiresAt:  r.now().Add(ttl),
+	}
+}
+
+// evict removes the expired entries, or the entry which expires first if none expired. The caller holds the lock.
+func (r *ResolverDNSValidator) evict() {
+	now := r.now()
+	var first string
+	for host, entry := range r.cache {
+		if !now.Before(entry.expiresAt) {
+			delete(r.cache, host)
+			continue
+		}
+		if first == "" || entry.expiresAt.Before(r.cache[first].expiresAt) {
+			first = host
+		}
+	}
+
+	if len(r.cache) >= maxDNSCacheEntries {
+		delete(r.cache, first)
+	}
+}
+
+// newResolver returns a resolver which sends the queries to the given nameservers in turn,
+// or the resolver of the system if no nameservers are given.
+func newResolver(nameservers []string) *net.Resolver {
+	if len(nameservers) == 0 {
+		return net.DefaultResolver
+	}
+
+	addresses := make([]string, 0, len(nameservers))
+	for _, nameserver := range nameservers {
+		if _, _, err := net.SplitHostPort(nameserver); err != nil {
+			nameserver = net.JoinHostPort(nameserver, "53")
+		}
+		addresses = append(addresses, nameserver)
+	}
+
+	var next atomic.Uint32
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			address := addresses[int(next.Add(1)-1)%len(addresses)]
+
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, address)
+		},
 	}
-	return false
 }
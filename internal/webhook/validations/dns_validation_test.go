@@ -0,0 +1,203 @@
+package validations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	registrycache "github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/webhook/validations/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestResolverDNSValidator(t *testing.T) {
+	const host = "registry.example.com"
+
+	t.Run("results are cached for the TTL", func(t *testing.T) {
+		validator, queries, clock := fixResolverDNSValidator(time.Minute, 10*time.Second)
+
+		require.False(t, validator.IsResolvable(context.Background(), host))
+		sent := queries.Load()
+		require.NotZero(t, sent)
+
+		require.False(t, validator.IsResolvable(context.Background(), host))
+		require.Equal(t, sent, queries.Load())
+
+		clock.Add(10 * time.Second)
+		require.False(t, validator.IsResolvable(context.Background(), host))
+		require.Greater(t, queries.Load(), sent)
+	})
+
+	t.Run("resolvable hosts are cached with the positive TTL", func(t *testing.T) {
+		validator, queries, clock := fixResolverDNSValidator(time.Minute, 10*time.Second)
+		validator.store(host, true)
+
+		clock.Add(30 * time.Second)
+		require.True(t, validator.IsResolvable(context.Background(), host))
+		require.Zero(t, queries.Load())
+
+		clock.Add(30 * time.Second)
+		require.False(t, validator.IsResolvable(context.Background(), host))
+		require.NotZero(t, queries.Load())
+	})
+
+	t.Run("caching is disabled with zero TTLs", func(t *testing.T) {
+		validator, queries, _ := fixResolverDNSValidator(0, 0)
+
+		require.False(t, validator.IsResolvable(context.Background(), host))
+		sent := queries.Load()
+
+		require.False(t, validator.IsResolvable(context.Background(), host))
+		require.Greater(t, queries.Load(), sent)
+	})
+
+	t.Run("the cache is bounded", func(t *testing.T) {
+		validator, _, clock := fixResolverDNSValidator(time.Minute, 10*time.Second)
+
+		validator.store("first.example.com", false)
+		clock.Add(time.Second)
+		for i := range maxDNSCacheEntries {
+			validator.store(fmt.Sprintf("host-%d.example.com", i), true)
+		}
+
+		require.Len(t, validator.cache, maxDNSCacheEntries)
+		_, cached := validator.cached("first.example.com")
+		require.False(t, cached, "the entry which expires first must be evicted")
+
+		clock.Add(time.Minute)
+		validator.store(host, true)
+		require.Len(t, validator.cache, 1, "expired entries must be removed")
+	})
+
+	t.Run("aborted lookups are not cached", func(t *testing.T) {
+		validator, _, _ := fixResolverDNSValidator(time.Minute, time.Minute)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		require.False(t, validator.IsResolvable(ctx, host))
+		_, cached := validator.cached(host)
+		require.False(t, cached)
+	})
+}
+
+func TestNewResolver(t *testing.T) {
+	t.Run("system resolver is used without nameservers", func(t *testing.T) {
+		require.Same(t, net.DefaultResolver, newResolver(nil))
+	})
+
+	t.Run("nameservers are used in turn", func(t *testing.T) {
+		var dialed []string
+		resolver := newResolver([]string{"127.0.0.1", "127.0.0.2:5353"})
+		for range 3 {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := resolver.Dial(ctx, "udp", "ignored:53")
+			var opErr *net.OpError
+			require.ErrorAs(t, err, &opErr)
+			dialed = append(dialed, opErr.Addr.String())
+		}
+
+		require.Equal(t, []string{"127.0.0.1:53", "127.0.0.2:5353", "127.0.0.1:53"}, dialed)
+	})
+}
+
+func TestParseDNSMode(t *testing.T) {
+	for _, value := range []string{"strict", "warn", "off"} {
+		mode, err := ParseDNSMode(value)
+		require.NoError(t, err)
+		require.Equal(t, DNSMode(value), mode)
+	}
+
+	_, err := ParseDNSMode("lenient")
+	require.Error(t, err)
+}
+
+func TestParseNameservers(t *testing.T) {
+	nameservers, err := ParseNameservers(" 10.96.0.10 , ,10.96.0.11:5353,fd00::10,[fd00::11]:53,")
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.96.0.10", "10.96.0.11:5353", "fd00::10", "[fd00::11]:53"}, nameservers)
+
+	for _, value := range []string{"kube-dns.kube-system", "10.96.0.10:dns", "10.96.0.10:70000", "10.96.0"} {
+		_, err := ParseNameservers(value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestValidatorDNSMode(t *testing.T) {
+	cfg := buildConfig("config1", "default", registrycache.RegistryCacheConfigSpec{
+		Upstream:  "registry-not-existing.not-exists.io",
+		RemoteURL: ptr.To("https://registry-not-existing.not-exists.io"),
+		Volume:    &registrycache.Volume{StorageClassName: ptr.To("standard")},
+	})
+	storageClass := buildStorageClass("standard", storagev1.VolumeBindingWaitForFirstConsumer, nil)
+
+	t.Run("strict mode rejects hosts which are not resolvable", func(t *testing.T) {
+		warnings, errs := NewValidator(newTestEnv().dnsResolver, fixFakeClient(&storageClass)).Do(context.Background(), &cfg)
+
+		require.Empty(t, warnings)
+		require.Len(t, errs, 2)
+	})
+
+	t.Run("warn mode returns warnings", func(t *testing.T) {
+		warnings, errs := NewValidator(newTestEnv().dnsResolver, fixFakeClient(&storageClass), WithDNSMode(DNSModeWarn)).Do(context.Background(), &cfg)
+
+		require.Empty(t, errs)
+		require.Equal(t, []string{
+			"RCW006: spec.upstream: upstream is not DNS resolvable",
+			"RCW006: spec.remoteURL: remoteURL is not DNS resolvable",
+		}, []string(warnings))
+	})
+
+	t.Run("off mode skips the lookups", func(t *testing.T) {
+		resolver := &mocks.DNSValidator{}
+
+		warnings, errs := NewValidator(resolver, fixFakeClient(&storageClass), WithDNSMode(DNSModeOff)).Do(context.Background(), &cfg)
+
+		require.Empty(t, warnings)
+		require.Empty(t, errs)
+		resolver.AssertNotCalled(t, "IsResolvable", mock.Anything, mock.Anything)
+	})
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// fixResolverDNSValidator returns a validator whose lookups always fail, and the counter of the sent queries.
+func fixResolverDNSValidator(positiveTTL, negativeTTL time.Duration) (*ResolverDNSValidator, *atomic.Int32, *fakeClock) {
+	var queries atomic.Int32
+	clock := &fakeClock{now: time.Now()}
+
+	validator := NewDNSValidator(DNSValidatorOptions{
+		Timeout:     time.Second,
+		PositiveTTL: positiveTTL,
+		NegativeTTL: negativeTTL,
+	})
+	validator.now = clock.Now
+	validator.resolver = &net.Resolver{
+		PreferGo: true,
+		Dial: func(_ context.Context, _, _ string) (net.Conn, error) {
+			queries.Add(1)
+			return nil, errors.New("no nameserver available")
+		},
+	}
+
+	return validator, &queries, clock
+}
@@ -2,24 +2,28 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
 
 // DNSValidator is an autogenerated mock type for the DNSValidator type
 type DNSValidator struct {
 	mock.Mock
 }
 
-// IsResolvable provides a mock function with given fields: host
-func (_m *DNSValidator) IsResolvable(host string) bool {
-	ret := _m.Called(host)
+// IsResolvable provides a mock function with given fields: ctx, host
+func (_m *DNSValidator) IsResolvable(ctx context.Context, host string) bool {
+	ret := _m.Called(ctx, host)
 
 	if len(ret) == 0 {
 		panic("no return value specified for IsResolvable")
 	}
 
 	var r0 bool
-	if rf, ok := ret.Get(0).(func(string) bool); ok {
-		r0 = rf(host)
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, host)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
@@ -0,0 +1,142 @@
+package validations
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	registrycache "github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/policy"
+	"github.com/kyma-project/registry-cache/internal/webhook/defaults"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// validatePolicy enforces the policy of the RegistryCache resource. The oldConfig is nil on creation.
+// On update only a changed upstream and a changed volume size are checked, so that existing configs stay editable
+// when the policy is tightened.
+func validatePolicy(ctx context.Context, newConfig, oldConfig *registrycache.RegistryCacheConfig, runtimeClient client.Client) field.ErrorList {
+	p, err := policy.Get(ctx, runtimeClient)
+	if err != nil {
+		return field.ErrorList{field.InternalError(field.NewPath("spec"), err)}
+	}
+	if p == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+
+	if newConfig.Spec.Upstream != "" && (oldConfig == nil || oldConfig.Spec.Upstream != newConfig.Spec.Upstream) &&
+		!policy.UpstreamAllowed(p, newConfig.Spec.Upstream) {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("upstream"),
+			fmt.Sprintf("upstream %s is not allowed by the policy of the RegistryCache", newConfig.Spec.Upstream)))
+	}
+
+	if oldConfig != nil {
+		newSize, oldSize := defaults.VolumeSize(newConfig.Spec), defaults.VolumeSize(oldConfig.Spec)
+		if newSize.Cmp(oldSize) != 0 {
+			allErrs = append(allErrs, validatePolicyStorage(ctx, newConfig, p, runtimeClient)...)
+		}
+		return allErrs
+	}
+
+	allErrs = append(allErrs, validatePolicyNamespace(ctx, newConfig, p, runtimeClient)...)
+	allErrs = append(allErrs, validatePolicyMaxConfigs(ctx, newConfig, p, runtimeClient)...)
+
+	return append(allErrs, validatePolicyStorage(ctx, newConfig, p, runtimeClient)...)
+}
+
+func validatePolicyNamespace(ctx context.Context, newConfig *registrycache.RegistryCacheConfig, p *registrycache.RegistryCachePolicy, runtimeClient client.Client) field.ErrorList {
+	if p.NamespaceSelector == nil {
+		return nil
+	}
+
+	var namespace v1.Namespace
+	if err := runtimeClient.Get(ctx, types.NamespacedName{Name: newConfig.Namespace}, &namespace); err != nil {
+		return field.ErrorList{field.InternalError(field.NewPath("metadata").Child("namespace"), errors.Wrap(err, "failed to get namespace"))}
+	}
+
+	if !policy.NamespaceAllowed(p, namespace.Labels) {
+		return field.ErrorList{field.Forbidden(field.NewPath("metadata").Child("namespace"),
+			fmt.Sprintf("namespace %s is not permitted by the policy of the RegistryCache", newConfig.Namespace))}
+	}
+
+	return nil
+}
+
+func validatePolicyMaxConfigs(ctx context.Context, newConfig *registrycache.RegistryCacheConfig, p *registrycache.RegistryCachePolicy, runtimeClient client.Client) field.ErrorList {
+	if p.MaxConfigs == nil {
+		return nil
+	}
+
+	others, err := listOtherConfigs(ctx, newConfig, runtimeClient)
+	if err != nil {
+		return field.ErrorList{field.InternalError(field.NewPath("metadata").Child("name"), err)}
+	}
+
+	if len(others) >= int(*p.MaxConfigs) {
+		return field.ErrorList{field.Forbidden(field.NewPath("metadata").Child("name"),
+			fmt.Sprintf("the policy of the RegistryCache allows at most %d RegistryCacheConfig resources", *p.MaxConfigs))}
+	}
+
+	return nil
+}
+
+func validatePolicyStorage(ctx context.Context, newConfig *registrycache.RegistryCacheConfig, p *registrycache.RegistryCachePolicy, runtimeClient client.Client) field.ErrorList {
+	if p.Storage == nil {
+		return nil
+	}
+
+	sizePath := field.NewPath("spec").Child("volume", "size")
+	size := defaults.VolumeSize(newConfig.Spec)
+
+	if p.Storage.MinVolumeSize != nil && size.Cmp(*p.Storage.MinVolumeSize) < 0 {
+		return field.ErrorList{field.Invalid(sizePath, size.String(),
+			fmt.Sprintf("volume size must be at least %s as required by the policy of the RegistryCache", p.Storage.MinVolumeSize.String()))}
+	}
+
+	if p.Storage.MaxVolumeSize != nil && size.Cmp(*p.Storage.MaxVolumeSize) > 0 {
+		return field.ErrorList{field.Invalid(sizePath, size.String(),
+			fmt.Sprintf("volume size must be at most %s as required by the policy of the RegistryCache", p.Storage.MaxVolumeSize.String()))}
+	}
+
+	if p.Storage.Budget == nil {
+		return nil
+	}
+
+	others, err := listOtherConfigs(ctx, newConfig, runtimeClient)
+	if err != nil {
+		return field.ErrorList{field.InternalError(sizePath, err)}
+	}
+
+	left := p.Storage.Budget.DeepCopy()
+	for _, other := range others {
+		left.Sub(defaults.VolumeSize(other.Spec))
+	}
+
+	if size.Cmp(left) > 0 {
+		if left.Sign() < 0 {
+			left = resource.MustParse("0")
+		}
+		return field.ErrorList{field.Forbidden(sizePath,
+			fmt.Sprintf("volume size %s exceeds the storage budget of the RegistryCache, %s of %s is left", size.String(), left.String(), p.Storage.Budget.String()))}
+	}
+
+	return nil
+}
+
+// listOtherConfigs returns all RegistryCacheConfig resources in the cluster except the validated one.
+func listOtherConfigs(ctx context.Context, newConfig *registrycache.RegistryCacheConfig, runtimeClient client.Client) ([]registrycache.RegistryCacheConfig, error) {
+	var existingConfigs registrycache.RegistryCacheConfigList
+	if err := runtimeClient.List(ctx, &existingConfigs); err != nil {
+		return nil, errors.Wrap(err, "failed to list existing registry cache configs")
+	}
+
+	return slices.DeleteFunc(existingConfigs.Items, func(existingConfig registrycache.RegistryCacheConfig) bool {
+		return existingConfig.Name == newConfig.Name && existingConfig.Namespace == newConfig.Namespace
+	}), nil
+}
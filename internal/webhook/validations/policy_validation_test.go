@@ -0,0 +1,189 @@
+package validations
+
+import (
+	"context"
+	"testing"
+
+	registrycache "github.com/kyma-project/registry-cache/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+)
+
+func TestValidatePolicy(t *testing.T) {
+	env := newTestEnv()
+
+	registryCache := &registrycache.RegistryCache{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "kyma-system"},
+		Spec: registrycache.RegistryCacheSpec{
+			Policy: &registrycache.RegistryCachePolicy{
+				AllowedUpstreams: []string{"docker.io", "*.example.com"},
+				DeniedUpstreams:  []string{"forbidden.example.com"},
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"registry-cache": "enabled"},
+				},
+				MaxConfigs: ptr.To[int32](2),
+			},
+		},
+	}
+	permittedNamespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default", Labels: map[string]string{"registry-cache": "enabled"}}}
+	otherNamespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}}
+
+	t.Run("config permitted by the policy", func(t *testing.T) {
+		cfg := buildConfig("config1", "default", registrycache.RegistryCacheConfigSpec{Upstream: "quay.example.com"})
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(registryCache, permittedNamespace)).Do(context.Background(), &cfg)
+
+		validateResult(t, nil, errs)
+	})
+
+	t.Run("upstream not allowed", func(t *testing.T) {
+		cfg := buildConfig("config1", "default", registrycache.RegistryCacheConfigSpec{Upstream: "quay.io"})
+		denied := buildConfig("config2", "default", registrycache.RegistryCacheConfigSpec{Upstream: "forbidden.example.com"})
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(registryCache, permittedNamespace)).Do(context.Background(), &cfg)
+		validateResult(t, field.ErrorList{
+			field.Forbidden(env.upstreamFieldPath, "upstream quay.io is not allowed by the policy of the RegistryCache"),
+		}, errs)
+
+		_, errs = NewValidator(env.dnsResolverAllOK, fixFakeClient(registryCache, permittedNamespace)).Do(context.Background(), &denied)
+		validateResult(t, field.ErrorList{
+			field.Forbidden(env.upstreamFieldPath, "upstream forbidden.example.com is not allowed by the policy of the RegistryCache"),
+		}, errs)
+	})
+
+	t.Run("namespace not permitted", func(t *testing.T) {
+		cfg := buildConfig("config1", "other", registrycache.RegistryCacheConfigSpec{Upstream: "docker.io"})
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(registryCache, otherNamespace)).Do(context.Background(), &cfg)
+
+		validateResult(t, field.ErrorList{
+			field.Forbidden(field.NewPath("metadata").Child("namespace"), "namespace other is not permitted by the policy of the RegistryCache"),
+		}, errs)
+	})
+
+	t.Run("maximum number of configs reached", func(t *testing.T) {
+		existing1 := buildConfig("existing1", "default", registrycache.RegistryCacheConfigSpec{Upstream: "a.example.com"})
+		existing2 := buildConfig("existing2", "default", registrycache.RegistryCacheConfigSpec{Upstream: "b.example.com"})
+		cfg := buildConfig("config1", "default", registrycache.RegistryCacheConfigSpec{Upstream: "docker.io"})
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(registryCache, permittedNamespace, &existing1, &existing2)).Do(context.Background(), &cfg)
+
+		validateResult(t, field.ErrorList{
+			field.Forbidden(field.NewPath("metadata").Child("name"), "the policy of the RegistryCache allows at most 2 RegistryCacheConfig resources"),
+		}, errs)
+	})
+
+	t.Run("existing configs stay editable when the policy is tightened", func(t *testing.T) {
+		oldCfg := buildConfig("config1", "other", registrycache.RegistryCacheConfigSpec{Upstream: "quay.io"})
+		existing := buildConfig("existing", "default", registrycache.RegistryCacheConfigSpec{Upstream: "a.example.com"})
+		newCfg := oldCfg.DeepCopy()
+		newCfg.Spec.Proxy = &registrycache.Proxy{HTTPSProxy: ptr.To("http://proxy.example.com:3128")}
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(registryCache, otherNamespace, &oldCfg, &existing)).DoOnUpdate(context.Background(), newCfg, &oldCfg)
+
+		validateResult(t, nil, errs)
+	})
+
+	t.Run("existing configs stay valid on revalidation when the policy is tightened", func(t *testing.T) {
+		existing1 := buildConfig("existing1", "default", registrycache.RegistryCacheConfigSpec{Upstream: "a.example.com"})
+		existing2 := buildConfig("existing2", "default", registrycache.RegistryCacheConfigSpec{Upstream: "b.example.com"})
+		cfg := buildConfig("config1", "other", registrycache.RegistryCacheConfigSpec{Upstream: "quay.io"})
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(registryCache, otherNamespace, &existing1, &existing2, &cfg)).Revalidate(context.Background(), &cfg)
+
+		validateResult(t, nil, errs)
+	})
+
+	t.Run("invalid policy is not enforced", func(t *testing.T) {
+		invalid := registryCache.DeepCopy()
+		invalid.Spec.Policy.AllowedUpstreams = []string{"[a-"}
+		cfg := buildConfig("config1", "other", registrycache.RegistryCacheConfigSpec{Upstream: "quay.io"})
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(invalid, otherNamespace)).Do(context.Background(), &cfg)
+
+		validateResult(t, nil, errs)
+	})
+}
+
+func TestValidatePolicyStorage(t *testing.T) {
+	env := newTestEnv()
+
+	registryCache := &registrycache.RegistryCache{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "kyma-system"},
+		Spec: registrycache.RegistryCacheSpec{
+			Policy: &registrycache.RegistryCachePolicy{
+				Storage: &registrycache.StoragePolicy{
+					MinVolumeSize: ptr.To(resource.MustParse("5Gi")),
+					MaxVolumeSize: ptr.To(resource.MustParse("50Gi")),
+					Budget:        ptr.To(resource.MustParse("60Gi")),
+				},
+			},
+		},
+	}
+	// the config without spec.volume.size counts with the default size of 10Gi
+	existing1 := buildConfig("existing1", "default", registrycache.RegistryCacheConfigSpec{Upstream: "a.example.com"})
+	existing2 := buildConfig("existing2", "other", registrycache.RegistryCacheConfigSpec{
+		Upstream: "b.example.com",
+		Volume:   &registrycache.Volume{Size: ptr.To(resource.MustParse("30Gi"))},
+	})
+
+	configWithSize := func(size string) registrycache.RegistryCacheConfig {
+		return buildConfig("config1", "default", registrycache.RegistryCacheConfigSpec{
+			Upstream: "docker.io",
+			Volume:   &registrycache.Volume{Size: ptr.To(resource.MustParse(size))},
+		})
+	}
+
+	t.Run("volume size within the bounds and the budget", func(t *testing.T) {
+		cfg := configWithSize("20Gi")
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(registryCache, &existing1, &existing2)).Do(context.Background(), &cfg)
+
+		validateResult(t, nil, errs)
+	})
+
+	t.Run("volume size below the minimum", func(t *testing.T) {
+		cfg := configWithSize("1Gi")
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(registryCache)).Do(context.Background(), &cfg)
+
+		validateResult(t, field.ErrorList{
+			field.Invalid(env.volumeSizeFieldPath, "1Gi", "volume size must be at least 5Gi"),
+		}, errs)
+	})
+
+	t.Run("volume size above the maximum", func(t *testing.T) {
+		cfg := configWithSize("100Gi")
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(registryCache)).Do(context.Background(), &cfg)
+
+		validateResult(t, field.ErrorList{
+			field.Invalid(env.volumeSizeFieldPath, "100Gi", "volume size must be at most 50Gi"),
+		}, errs)
+	})
+
+	t.Run("storage budget exceeded", func(t *testing.T) {
+		cfg := configWithSize("25Gi")
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(registryCache, &existing1, &existing2)).Do(context.Background(), &cfg)
+
+		validateResult(t, field.ErrorList{
+			field.Forbidden(env.volumeSizeFieldPath, "volume size 25Gi exceeds the storage budget of the RegistryCache, 20Gi of 60Gi is left"),
+		}, errs)
+	})
+
+	t.Run("default volume size counts against the budget", func(t *testing.T) {
+		full := registryCache.DeepCopy()
+		full.Spec.Policy.Storage.Budget = ptr.To(resource.MustParse("45Gi"))
+		cfg := buildConfig("config1", "default", registrycache.RegistryCacheConfigSpec{Upstream: "docker.io"})
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(full, &existing1, &existing2)).Do(context.Background(), &cfg)
+
+		validateResult(t, field.ErrorList{
+			field.Forbidden(env.volumeSizeFieldPath, "volume size 10Gi exceeds the storage budget of the RegistryCache, 5Gi of 45Gi is left"),
+		}, errs)
+	})
+}
@@ -0,0 +1,58 @@
+package validations
+
+import (
+	"fmt"
+	"strings"
+
+	registrycache "github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/upstream"
+	"github.com/robfig/cron/v3"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// validatePrewarm checks that the images of spec.prewarm are hosted by the upstream, that the workload namespace selector
+// is valid, and that the schedule is in the cron format.
+func validatePrewarm(newConfig *registrycache.RegistryCacheConfig) field.ErrorList {
+	prewarm := newConfig.Spec.Prewarm
+	if prewarm == nil {
+		return nil
+	}
+
+	fldPath := field.NewPath("spec").Child("prewarm")
+
+	var allErrs field.ErrorList
+	imagesPath := fldPath.Child("images")
+	seen := sets.New[string]()
+	for i, image := range prewarm.Images {
+		if image == "" || strings.ContainsAny(image, " \t\n") {
+			allErrs = append(allErrs, field.Invalid(imagesPath.Index(i), image, "must be an image reference"))
+			continue
+		}
+		if seen.Has(image) {
+			allErrs = append(allErrs, field.Duplicate(imagesPath.Index(i), image))
+		}
+		seen.Insert(image)
+		if newConfig.Spec.Upstream != "" && upstream.FromImage(image) != upstream.Normalize(newConfig.Spec.Upstream) {
+			allErrs = append(allErrs, field.Invalid(imagesPath.Index(i), image, fmt.Sprintf("image must be hosted by the upstream %s", newConfig.Spec.Upstream)))
+		}
+	}
+
+	if prewarm.WorkloadSelector != nil {
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(prewarm.WorkloadSelector,
+			metav1validation.LabelSelectorValidationOptions{}, fldPath.Child("workloadSelector"))...)
+	}
+
+	if prewarm.Schedule != nil {
+		if _, err := cron.ParseStandard(*prewarm.Schedule); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("schedule"), *prewarm.Schedule, fmt.Sprintf("must be a cron schedule: %v", err)))
+		}
+	}
+
+	if len(prewarm.Images) == 0 && prewarm.WorkloadSelector == nil {
+		allErrs = append(allErrs, field.Required(fldPath, "images or workloadSelector must be set"))
+	}
+
+	return allErrs
+}
@@ -0,0 +1,78 @@
+package validations
+
+import (
+	"context"
+	"testing"
+
+	registrycache "github.com/kyma-project/registry-cache/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+)
+
+func TestValidatePrewarm(t *testing.T) {
+	env := newTestEnv()
+	prewarmPath := field.NewPath("spec").Child("prewarm")
+
+	configWithPrewarm := func(upstream string, prewarm *registrycache.Prewarm) registrycache.RegistryCacheConfig {
+		return buildConfig("config1", "default", registrycache.RegistryCacheConfigSpec{
+			Upstream: upstream,
+			Prewarm:  prewarm,
+		})
+	}
+
+	t.Run("valid prewarm settings", func(t *testing.T) {
+		cfg := configWithPrewarm("docker.io", &registrycache.Prewarm{
+			Images: []string{"nginx:1.27", "library/redis:7", "registry-1.docker.io/org/app@sha256:0123456789abcdef"},
+			WorkloadSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"prewarm": "true"},
+			},
+			Schedule: ptr.To("0 3 * * *"),
+		})
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient()).Do(context.Background(), &cfg)
+
+		validateResult(t, nil, errs)
+	})
+
+	t.Run("images of other upstreams and duplicates", func(t *testing.T) {
+		cfg := configWithPrewarm("quay.io", &registrycache.Prewarm{
+			Images: []string{"quay.io/org/app:v1", "gcr.io/org/app:v1", "quay.io/org/app:v1", "org/app:v1", "quay.io/org/app :v1"},
+		})
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient()).Do(context.Background(), &cfg)
+
+		validateResult(t, field.ErrorList{
+			field.Invalid(prewarmPath.Child("images").Index(1), "gcr.io/org/app:v1", "image must be hosted by the upstream quay.io"),
+			field.Duplicate(prewarmPath.Child("images").Index(2), "quay.io/org/app:v1"),
+			field.Invalid(prewarmPath.Child("images").Index(3), "org/app:v1", "image must be hosted by the upstream quay.io"),
+			field.Invalid(prewarmPath.Child("images").Index(4), "quay.io/org/app :v1", "must be an image reference"),
+		}, errs)
+	})
+
+	t.Run("invalid schedule and selector", func(t *testing.T) {
+		cfg := configWithPrewarm("quay.io", &registrycache.Prewarm{
+			WorkloadSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "prewarm", Operator: "Equals"}},
+			},
+			Schedule: ptr.To("every night"),
+		})
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient()).Do(context.Background(), &cfg)
+
+		validateResult(t, field.ErrorList{
+			field.Invalid(prewarmPath.Child("workloadSelector", "matchExpressions").Index(0).Child("operator"), metav1.LabelSelectorOperator("Equals"), "not a valid selector operator"),
+			field.Invalid(prewarmPath.Child("schedule"), "every night", "must be a cron schedule"),
+		}, errs)
+	})
+
+	t.Run("nothing to pull", func(t *testing.T) {
+		cfg := configWithPrewarm("quay.io", &registrycache.Prewarm{Schedule: ptr.To("@daily")})
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient()).Do(context.Background(), &cfg)
+
+		validateResult(t, field.ErrorList{
+			field.Required(prewarmPath, "images or workloadSelector must be set"),
+		}, errs)
+	})
+}
@@ -0,0 +1,38 @@
+package validations
+
+import (
+	"net/url"
+
+	registrycache "github.com/kyma-project/registry-cache/api/v1beta1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// validateProxy checks what the extension validations of spec.proxy do not cover: credentials embedded in the proxy URLs.
+func validateProxy(newConfig *registrycache.RegistryCacheConfig) field.ErrorList {
+	proxy := newConfig.Spec.Proxy
+	if proxy == nil {
+		return nil
+	}
+
+	fldPath := field.NewPath("spec").Child("proxy")
+
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validateProxyURLWithoutCredentials(proxy.HTTPProxy, fldPath.Child("httpProxy"))...)
+	allErrs = append(allErrs, validateProxyURLWithoutCredentials(proxy.HTTPSProxy, fldPath.Child("httpsProxy"))...)
+
+	return allErrs
+}
+
+// validateProxyURLWithoutCredentials rejects proxy URLs with user information, the error does not echo the URL to keep the password out of logs.
+func validateProxyURLWithoutCredentials(proxyURL *string, fldPath *field.Path) field.ErrorList {
+	if proxyURL == nil {
+		return nil
+	}
+
+	parsed, err := url.Parse(*proxyURL)
+	if err != nil || parsed.User == nil {
+		return nil
+	}
+
+	return field.ErrorList{field.Forbidden(fldPath, "must not contain credentials, they would be stored in plain text in the spec")}
+}
@@ -0,0 +1,79 @@
+package validations
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	registrycacheextvalidations "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/validation"
+	registrycache "github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/credentials"
+	"github.com/kyma-project/registry-cache/internal/index"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ValidateSecretDeletion reports the RegistryCacheConfigs in the namespace of the Secret which still reference it.
+// The runtimeClient must provide the index.RegistryCacheConfigSecretReferenceName field index.
+// Deleting the Secret is allowed when the namespace is terminating or all referencing configs are being deleted,
+// so that namespace cleanup is never blocked.
+func ValidateSecretDeletion(ctx context.Context, runtimeClient client.Client, secret *v1.Secret) field.ErrorList {
+	namePath := field.NewPath("metadata").Child("name")
+
+	var namespace v1.Namespace
+	if err := runtimeClient.Get(ctx, types.NamespacedName{Name: secret.Namespace}, &namespace); err != nil {
+		return field.ErrorList{field.InternalError(namePath, errors.Wrap(err, "failed to get namespace"))}
+	}
+	if namespace.Status.Phase == v1.NamespaceTerminating || !namespace.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	var configs registrycache.RegistryCacheConfigList
+	if err := runtimeClient.List(ctx, &configs,
+		client.InNamespace(secret.Namespace),
+		client.MatchingFields{index.RegistryCacheConfigSecretReferenceName: secret.Name}); err != nil {
+		return field.ErrorList{field.InternalError(namePath, errors.Wrap(err, "failed to list existing registry cache configs"))}
+	}
+
+	var referencingConfigs []string
+	for _, config := range configs.Items {
+		if config.DeletionTimestamp.IsZero() {
+			referencingConfigs = append(referencingConfigs, config.Name)
+		}
+	}
+
+	if len(referencingConfigs) == 0 {
+		return nil
+	}
+
+	slices.Sort(referencingConfigs)
+
+	return field.ErrorList{field.Forbidden(namePath,
+		fmt.Sprintf("secret %s is referenced by RegistryCacheConfig %s", secret.Name, strings.Join(referencingConfigs, ", ")))}
+}
+
+// validateDerivableSecret checks that the credentials for the upstream can be extracted from a dockerconfigjson or basic-auth Secret,
+// and that the Secret derived from them passes the validation of Secrets in the canonical format.
+// Unlike Secrets in the canonical format, these Secrets may be mutable, as the derived Secret is recreated when they change.
+func validateDerivableSecret(secret *v1.Secret, newConfig *registrycache.RegistryCacheConfig) field.ErrorList {
+	fldPath := field.NewPath("spec").Child("secretReferenceName")
+	if newConfig.Spec.Upstream == "" {
+		return nil
+	}
+
+	creds, err := credentials.Extract(secret, newConfig.Spec.Upstream)
+	if err != nil {
+		return field.ErrorList{field.Invalid(fldPath, secret.Name,
+			fmt.Sprintf("failed to extract the credentials from secret %s of type %s: %v", secret.Name, secret.Type, err))}
+	}
+
+	// validate the derived Secret under the name of the referenced one, so that the errors point to the Secret of the user
+	derived := credentials.DerivedSecret(newConfig.Name, secret.Namespace, creds)
+	derived.Name = secret.Name
+
+	return registrycacheextvalidations.ValidateUpstreamRegistrySecret(derived, fldPath, secret.Name)
+}
@@ -0,0 +1,141 @@
+package validations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	registrycache "github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+)
+
+func TestValidateSecretDeletion(t *testing.T) {
+	secret := buildSecret("upstream-secret", "default", true, map[string][]byte{
+		"username": []byte("user"),
+		"password": []byte("password"),
+	})
+	namespace := buildNamespace("default", v1.NamespaceActive)
+
+	referencingConfig := func(name string) *registrycache.RegistryCacheConfig {
+		config := buildConfig(name, "default", registrycache.RegistryCacheConfigSpec{
+			Upstream:            name + ".io",
+			SecretReferenceName: ptr.To("upstream-secret"),
+		})
+		return &config
+	}
+
+	t.Run("secret without references", func(t *testing.T) {
+		unrelatedConfig := buildConfig("unrelated", "default", registrycache.RegistryCacheConfigSpec{
+			Upstream:            "quay.io",
+			SecretReferenceName: ptr.To("other-secret"),
+		})
+		otherNamespaceConfig := referencingConfig("other-namespace")
+		otherNamespaceConfig.Namespace = "other"
+
+		errs := ValidateSecretDeletion(context.Background(), fixFakeClient(&namespace, &unrelatedConfig, otherNamespaceConfig), &secret)
+
+		require.Empty(t, errs)
+	})
+
+	t.Run("secret referenced by configs", func(t *testing.T) {
+		errs := ValidateSecretDeletion(context.Background(), fixFakeClient(&namespace, referencingConfig("quay"), referencingConfig("ghcr")), &secret)
+
+		validateResult(t, field.ErrorList{
+			field.Forbidden(field.NewPath("metadata").Child("name"), "secret upstream-secret is referenced by RegistryCacheConfig ghcr, quay"),
+		}, errs)
+	})
+
+	t.Run("referencing configs being deleted", func(t *testing.T) {
+		config := referencingConfig("quay")
+		config.Finalizers = []string{"test"}
+		config.DeletionTimestamp = ptr.To(metav1.NewTime(time.Now()))
+
+		errs := ValidateSecretDeletion(context.Background(), fixFakeClient(&namespace, config), &secret)
+
+		require.Empty(t, errs)
+	})
+
+	t.Run("namespace terminating", func(t *testing.T) {
+		terminatingNamespace := buildNamespace("default", v1.NamespaceTerminating)
+
+		errs := ValidateSecretDeletion(context.Background(), fixFakeClient(&terminatingNamespace, referencingConfig("quay")), &secret)
+
+		require.Empty(t, errs)
+	})
+}
+
+func TestValidateDerivableSecret(t *testing.T) {
+	env := newTestEnv()
+
+	dockerConfig := buildSecret("pull-secret", "default", false, map[string][]byte{
+		v1.DockerConfigJsonKey: []byte(`{"auths": {"https://index.docker.io/v1/": {"username": "user", "password": "password"}}}`),
+	})
+	dockerConfig.Type = v1.SecretTypeDockerConfigJson
+
+	basicAuth := buildSecret("basic-auth", "default", false, map[string][]byte{
+		v1.BasicAuthUsernameKey: []byte("user"),
+		v1.BasicAuthPasswordKey: []byte("password"),
+	})
+	basicAuth.Type = v1.SecretTypeBasicAuth
+
+	configWithSecret := func(upstream, secretName string) registrycache.RegistryCacheConfig {
+		return buildConfig("config1", "default", registrycache.RegistryCacheConfigSpec{
+			Upstream:            upstream,
+			SecretReferenceName: ptr.To(secretName),
+		})
+	}
+
+	t.Run("mutable dockerconfigjson secret with credentials for the upstream", func(t *testing.T) {
+		cfg := configWithSecret("docker.io", dockerConfig.Name)
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&dockerConfig)).Do(context.Background(), &cfg)
+
+		validateResult(t, nil, errs)
+	})
+
+	t.Run("dockerconfigjson secret without credentials for the upstream", func(t *testing.T) {
+		cfg := configWithSecret("quay.io", dockerConfig.Name)
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&dockerConfig)).Do(context.Background(), &cfg)
+
+		validateResult(t, field.ErrorList{
+			field.Invalid(fieldPathSpec("secretReferenceName"), dockerConfig.Name,
+				"failed to extract the credentials from secret pull-secret of type kubernetes.io/dockerconfigjson: no credentials for upstream quay.io"),
+		}, errs)
+	})
+
+	t.Run("mutable basic-auth secret", func(t *testing.T) {
+		cfg := configWithSecret("quay.io", basicAuth.Name)
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&basicAuth)).Do(context.Background(), &cfg)
+
+		validateResult(t, nil, errs)
+	})
+
+	t.Run("basic-auth secret with empty password", func(t *testing.T) {
+		invalid := basicAuth.DeepCopy()
+		invalid.Data[v1.BasicAuthPasswordKey] = []byte(" ")
+		cfg := configWithSecret("quay.io", basicAuth.Name)
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(invalid)).Do(context.Background(), &cfg)
+
+		validateResult(t, field.ErrorList{
+			field.Invalid(fieldPathSpec("secretReferenceName"), basicAuth.Name, "the data entry \"password\" in the referenced secret \"default/basic-auth\" is empty"),
+		}, errs)
+	})
+}
+
+func buildNamespace(name string, phase v1.NamespacePhase) v1.Namespace {
+	return v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Status: v1.NamespaceStatus{
+			Phase: phase,
+		},
+	}
+}
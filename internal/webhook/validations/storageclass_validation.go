@@ -0,0 +1,132 @@
+package validations
+
+import (
+	"context"
+	"fmt"
+
+	registrycache "github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// AnnotationStorageClassDeprecated is set to "true" by cluster operators on a StorageClass which must not be used
+	// for new registry caches, for example, because it is going to be removed.
+	AnnotationStorageClassDeprecated = "registry-cache.kyma-project.io/deprecated"
+	// AnnotationDefaultedStorageClass is set by the defaulting webhook on a RegistryCacheConfig to the name of the default
+	// StorageClass which it filled in, so that the validation can name the class in its warning.
+	AnnotationDefaultedStorageClass = "registry-cache.kyma-project.io/defaulted-storage-class"
+
+	annotationDefaultStorageClass     = "storageclass.kubernetes.io/is-default-class"
+	annotationBetaDefaultStorageClass = "storageclass.beta.kubernetes.io/is-default-class"
+)
+
+// validateStorageClass checks the StorageClass of the cache volume, or the default StorageClass of the cluster
+// if spec.volume.storageClassName is not set. The defaulting webhook sets the name to the default StorageClass on creation,
+// so the name is only unset if the cluster has no default StorageClass or the config was not defaulted.
+// The warning names the default StorageClass which is used, whether the defaulting webhook filled it in or not.
+func validateStorageClass(ctx context.Context, newConfig *registrycache.RegistryCacheConfig, runtimeClient client.Client) (admission.Warnings, field.ErrorList) {
+	fldPath := field.NewPath("spec").Child("volume", "storageClassName")
+
+	if newConfig.Spec.Volume == nil || newConfig.Spec.Volume.StorageClassName == nil {
+		storageClass, err := DefaultStorageClass(ctx, runtimeClient)
+		if err != nil {
+			return nil, field.ErrorList{field.InternalError(fldPath, err)}
+		}
+		if storageClass == nil {
+			return admission.Warnings{formatWarning(WarningDefaultStorageClass, fldPath,
+				"not set and the cluster has no default storage class, the volume cannot be provisioned until a default storage class exists")}, nil
+		}
+
+		warnings := admission.Warnings{formatWarning(WarningDefaultStorageClass, fldPath,
+			fmt.Sprintf("not set, the default storage class %s is used", storageClass.Name))}
+
+		return warnings, validateStorageClassCapabilities(ctx, storageClass, fldPath, runtimeClient)
+	}
+
+	name := *newConfig.Spec.Volume.StorageClassName
+	var warnings admission.Warnings
+	if newConfig.Annotations[AnnotationDefaultedStorageClass] == name {
+		warnings = admission.Warnings{formatWarning(WarningDefaultStorageClass, fldPath,
+			fmt.Sprintf("not set, the default storage class %s is used", name))}
+	}
+
+	var storageClass storagev1.StorageClass
+	if err := runtimeClient.Get(ctx, types.NamespacedName{Name: name}, &storageClass); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, field.ErrorList{field.Invalid(fldPath, name, fmt.Sprintf("storage class %s does not exist", name))}
+		}
+
+		return nil, field.ErrorList{field.InternalError(fldPath, errors.Wrap(err, "failed to get storage class"))}
+	}
+
+	return warnings, validateStorageClassCapabilities(ctx, &storageClass, fldPath, runtimeClient)
+}
+
+func validateStorageClassCapabilities(ctx context.Context, storageClass *storagev1.StorageClass, fldPath *field.Path, runtimeClient client.Client) field.ErrorList {
+	if storageClass.Annotations[AnnotationStorageClassDeprecated] == "true" {
+		return field.ErrorList{field.Forbidden(fldPath, fmt.Sprintf("storage class %s is deprecated for registry caches", storageClass.Name))}
+	}
+
+	if storageClass.VolumeBindingMode != nil && *storageClass.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer {
+		return nil
+	}
+
+	multiZone, err := nodesSpanSeveralZones(ctx, runtimeClient)
+	if err != nil {
+		return field.ErrorList{field.InternalError(fldPath, err)}
+	}
+	if multiZone {
+		return field.ErrorList{field.Invalid(fldPath, storageClass.Name,
+			fmt.Sprintf("storage class %s does not use the %s volume binding mode, which zonal disks require when the nodes span several zones",
+				storageClass.Name, storagev1.VolumeBindingWaitForFirstConsumer))}
+	}
+
+	return nil
+}
+
+// DefaultStorageClass returns the default StorageClass of the cluster, or nil if there is none.
+// If several StorageClasses are marked as default, the newest one is used, as Kubernetes does.
+func DefaultStorageClass(ctx context.Context, runtimeClient client.Client) (*storagev1.StorageClass, error) {
+	var storageClasses storagev1.StorageClassList
+	if err := runtimeClient.List(ctx, &storageClasses); err != nil {
+		return nil, errors.Wrap(err, "failed to list storage classes")
+	}
+
+	var defaultClass *storagev1.StorageClass
+	for i, storageClass := range storageClasses.Items {
+		if storageClass.Annotations[annotationDefaultStorageClass] != "true" && storageClass.Annotations[annotationBetaDefaultStorageClass] != "true" {
+			continue
+		}
+		if defaultClass == nil || defaultClass.CreationTimestamp.Before(&storageClass.CreationTimestamp) {
+			defaultClass = &storageClasses.Items[i]
+		}
+	}
+
+	return defaultClass, nil
+}
+
+// nodesSpanSeveralZones lists the metadata of the Nodes only, so that the cached client keeps only their labels.
+func nodesSpanSeveralZones(ctx context.Context, runtimeClient client.Client) (bool, error) {
+	var nodes metav1.PartialObjectMetadataList
+	nodes.SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("NodeList"))
+	if err := runtimeClient.List(ctx, &nodes); err != nil {
+		return false, errors.Wrap(err, "failed to list nodes")
+	}
+
+	zones := map[string]struct{}{}
+	for _, node := range nodes.Items {
+		if zone, ok := node.Labels[v1.LabelTopologyZone]; ok {
+			zones[zone] = struct{}{}
+		}
+	}
+
+	return len(zones) > 1, nil
+}
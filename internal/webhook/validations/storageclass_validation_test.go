@@ -0,0 +1,158 @@
+package validations
+
+import (
+	"context"
+	"testing"
+
+	registrycache "github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+)
+
+func TestValidateStorageClass(t *testing.T) {
+	env := newTestEnv()
+
+	waitForFirstConsumer := buildStorageClass("zonal", storagev1.VolumeBindingWaitForFirstConsumer, nil)
+	immediate := buildStorageClass("immediate", storagev1.VolumeBindingImmediate, nil)
+	defaultClass := buildStorageClass("default", storagev1.VolumeBindingWaitForFirstConsumer, map[string]string{"storageclass.kubernetes.io/is-default-class": "true"})
+	nodeZoneA := buildNode("node-a", "zone-a")
+	nodeZoneB := buildNode("node-b", "zone-b")
+
+	configWithStorageClass := func(storageClassName *string) registrycache.RegistryCacheConfig {
+		return buildConfig("config1", "default", registrycache.RegistryCacheConfigSpec{
+			Upstream: "quay.io",
+			Volume:   &registrycache.Volume{StorageClassName: storageClassName},
+		})
+	}
+
+	t.Run("existing storage class", func(t *testing.T) {
+		cfg := configWithStorageClass(ptr.To("zonal"))
+
+		warnings, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&waitForFirstConsumer, &nodeZoneA, &nodeZoneB)).Do(context.Background(), &cfg)
+
+		require.Empty(t, warnings)
+		validateResult(t, nil, errs)
+	})
+
+	t.Run("storage class does not exist", func(t *testing.T) {
+		cfg := configWithStorageClass(ptr.To("zonnal"))
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&waitForFirstConsumer)).Do(context.Background(), &cfg)
+
+		validateResult(t, field.ErrorList{
+			field.Invalid(env.volumeStorageClassNameFieldPath, "zonnal", "storage class zonnal does not exist"),
+		}, errs)
+	})
+
+	t.Run("immediate binding is rejected when the nodes span several zones", func(t *testing.T) {
+		cfg := configWithStorageClass(ptr.To("immediate"))
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&immediate, &nodeZoneA, &nodeZoneB)).Do(context.Background(), &cfg)
+
+		validateResult(t, field.ErrorList{
+			field.Invalid(env.volumeStorageClassNameFieldPath, "immediate", "storage class immediate does not use the WaitForFirstConsumer volume binding mode"),
+		}, errs)
+	})
+
+	t.Run("immediate binding is accepted in a single zone", func(t *testing.T) {
+		cfg := configWithStorageClass(ptr.To("immediate"))
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&immediate, &nodeZoneA)).Do(context.Background(), &cfg)
+
+		validateResult(t, nil, errs)
+	})
+
+	t.Run("default storage class is used if not set", func(t *testing.T) {
+		cfg := configWithStorageClass(nil)
+
+		warnings, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&defaultClass, &immediate)).Do(context.Background(), &cfg)
+
+		require.Equal(t, []string{"RCW007: spec.volume.storageClassName: not set, the default storage class default is used"}, []string(warnings))
+		validateResult(t, nil, errs)
+	})
+
+	t.Run("default storage class filled in by the defaulting webhook is named", func(t *testing.T) {
+		cfg := configWithStorageClass(ptr.To("default"))
+		cfg.Annotations = map[string]string{AnnotationDefaultedStorageClass: "default"}
+
+		warnings, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&defaultClass, &immediate)).Do(context.Background(), &cfg)
+
+		require.Equal(t, []string{"RCW007: spec.volume.storageClassName: not set, the default storage class default is used"}, []string(warnings))
+		validateResult(t, nil, errs)
+	})
+
+	t.Run("deprecated storage class is rejected", func(t *testing.T) {
+		deprecated := buildStorageClass("deprecated", storagev1.VolumeBindingWaitForFirstConsumer, map[string]string{AnnotationStorageClassDeprecated: "true"})
+		cfg := configWithStorageClass(ptr.To("deprecated"))
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&deprecated)).Do(context.Background(), &cfg)
+
+		validateResult(t, field.ErrorList{
+			field.Forbidden(env.volumeStorageClassNameFieldPath, "storage class deprecated is deprecated for registry caches"),
+		}, errs)
+	})
+
+	t.Run("default storage class is checked", func(t *testing.T) {
+		immediateDefault := defaultClass.DeepCopy()
+		immediateDefault.VolumeBindingMode = ptr.To(storagev1.VolumeBindingImmediate)
+		cfg := configWithStorageClass(nil)
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(immediateDefault, &nodeZoneA, &nodeZoneB)).Do(context.Background(), &cfg)
+
+		validateResult(t, field.ErrorList{
+			field.Invalid(env.volumeStorageClassNameFieldPath, "default", "storage class default does not use the WaitForFirstConsumer volume binding mode"),
+		}, errs)
+	})
+
+	t.Run("no default storage class", func(t *testing.T) {
+		cfg := configWithStorageClass(nil)
+
+		warnings, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&immediate)).Do(context.Background(), &cfg)
+
+		require.Len(t, warnings, 1)
+		require.Contains(t, warnings[0], "the cluster has no default storage class")
+		validateResult(t, nil, errs)
+	})
+
+	t.Run("storage class is not checked on update", func(t *testing.T) {
+		oldCfg := configWithStorageClass(ptr.To("immediate"))
+		newCfg := oldCfg.DeepCopy()
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&immediate, &nodeZoneA, &nodeZoneB, &oldCfg)).DoOnUpdate(context.Background(), newCfg, &oldCfg)
+
+		validateResult(t, nil, errs)
+	})
+
+	t.Run("storage class is not checked on revalidation", func(t *testing.T) {
+		cfg := configWithStorageClass(ptr.To("immediate"))
+
+		warnings, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&immediate, &nodeZoneA, &nodeZoneB, &cfg)).Revalidate(context.Background(), &cfg)
+
+		require.Empty(t, warnings)
+		validateResult(t, nil, errs)
+	})
+}
+
+func buildStorageClass(name string, bindingMode storagev1.VolumeBindingMode, annotations map[string]string) storagev1.StorageClass {
+	return storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: annotations,
+		},
+		Provisioner:       "disk.csi.example.com",
+		VolumeBindingMode: ptr.To(bindingMode),
+	}
+}
+
+func buildNode(name, zone string) v1.Node {
+	return v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{v1.LabelTopologyZone: zone},
+		},
+	}
+}
@@ -6,66 +6,241 @@ import (
 	"github.com/pkg/errors"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
-	"net"
 	"net/url"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"strings"
+	"time"
 
 	registrycacheext "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry"
 	registrycacheextvalidations "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry/validation"
 	registrycache "github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/credentials"
+	"github.com/kyma-project/registry-cache/internal/index"
+	"github.com/kyma-project/registry-cache/internal/upstream"
+	"github.com/kyma-project/registry-cache/internal/webhook/defaults"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
+// extensionUserInfoDetail is the detail of the error the extension validations report for URLs with user information.
+const extensionUserInfoDetail = "url must not contain user info"
+
 // Validator validates RegistryCacheConfig resources.
 type Validator struct {
-	dnsValidator  DNSValidator
-	runtimeClient client.Client
+	dnsValidator        DNSValidator
+	runtimeClient       client.Client
+	credentialsVerifier CredentialsVerifier
+	timeout             time.Duration
+	dnsMode             DNSMode
+}
+
+// Option configures optional validations of the Validator.
+type Option func(*Validator)
+
+// WithCredentialsVerifier enables the verification of the referenced upstream credentials against the registry.
+func WithCredentialsVerifier(verifier CredentialsVerifier) Option {
+	return func(v *Validator) {
+		v.credentialsVerifier = verifier
+	}
+}
+
+// WithTimeout sets the time budget of the checks if the context of the validation carries no deadline.
+func WithTimeout(timeout time.Duration) Option {
+	return func(v *Validator) {
+		v.timeout = timeout
+	}
+}
+
+// WithDNSMode sets how hosts which are not DNS resolvable are handled, DNSModeStrict is used by default.
+func WithDNSMode(mode DNSMode) Option {
+	return func(v *Validator) {
+		v.dnsMode = mode
+	}
 }
 
 // NewValidator constructs a Validator with provided secrets and existing configs.
-func NewValidator(dnsValidator DNSValidator, runtimeClient client.Client) Validator {
-	return Validator{
+func NewValidator(dnsValidator DNSValidator, runtimeClient client.Client, opts ...Option) Validator {
+	v := Validator{
 		dnsValidator:  dnsValidator,
 		runtimeClient: runtimeClient,
+		timeout:       DefaultTimeout,
+		dnsMode:       DNSModeStrict,
+	}
+	for _, opt := range opts {
+		opt(&v)
 	}
+
+	return v
 }
 
-func (v Validator) Do(newConfig *registrycache.RegistryCacheConfig) field.ErrorList {
+// Do validates a new RegistryCacheConfig. The warnings hold the failed DNS checks in DNSModeWarn.
+func (v Validator) Do(ctx context.Context, newConfig *registrycache.RegistryCacheConfig) (admission.Warnings, field.ErrorList) {
 	if isEmptySpec(newConfig.Spec) {
-		return field.ErrorList{field.Required(field.NewPath("spec"), "spec must not be empty")}
+		return nil, field.ErrorList{field.Required(field.NewPath("spec"), "spec must not be empty")}
 	}
 
-	allErrs := v.validateCommon(newConfig)
+	return v.validate(ctx, newConfig, true)
+}
+
+// Revalidate validates a stored RegistryCacheConfig again. The checks which apply only on creation and on changes,
+// like the ones of the storage class and of the RegistryCache policy, are skipped, so that an existing config does not
+// fail on a change of the cluster or of the policy which it cannot follow. The warnings hold the failed DNS checks in DNSModeWarn.
+func (v Validator) Revalidate(ctx context.Context, config *registrycache.RegistryCacheConfig) (admission.Warnings, field.ErrorList) {
+	if isEmptySpec(config.Spec) {
+		return nil, field.ErrorList{field.Required(field.NewPath("spec"), "spec must not be empty")}
+	}
+
+	return v.validate(ctx, config, false)
+}
+
+func (v Validator) validate(ctx context.Context, newConfig *registrycache.RegistryCacheConfig, onCreation bool) (admission.Warnings, field.ErrorList) {
+	warnings, allErrs := v.validateCommon(ctx, newConfig, nil, onCreation)
 
 	gardenerValidations := registrycacheextvalidations.ValidateRegistryConfig(toExtensionConfig(*newConfig), field.NewPath("spec"))
 
-	return append(allErrs, transformFieldErrors(gardenerValidations)...)
+	return warnings, append(allErrs, transformFieldErrors(gardenerValidations)...)
 }
 
-func (v Validator) DoOnUpdate(newConfig, oldConfig *registrycache.RegistryCacheConfig) field.ErrorList {
+// DoOnUpdate validates an update of a RegistryCacheConfig. The warnings hold the failed DNS checks in DNSModeWarn.
+func (v Validator) DoOnUpdate(ctx context.Context, newConfig, oldConfig *registrycache.RegistryCacheConfig) (admission.Warnings, field.ErrorList) {
 	if isEmptySpec(newConfig.Spec) {
-		return field.ErrorList{field.Required(field.NewPath("spec"), "spec must not be empty")}
+		return nil, field.ErrorList{field.Required(field.NewPath("spec"), "spec must not be empty")}
 	}
 
-	allErrs := v.validateCommon(newConfig)
+	warnings, allErrs := v.validateCommon(ctx, newConfig, oldConfig, false)
+
+	// Objects stored before defaulting was introduced carry nil pointers for the optional fields,
+	// compare the effective values so that an unchanged field is not reported as modified.
+	oldDefaulted, newDefaulted := oldConfig.DeepCopy(), newConfig.DeepCopy()
+	defaults.SetDefaults(&oldDefaulted.Spec)
+	defaults.SetDefaults(&newDefaulted.Spec)
+
+	// The extension rejects any change of the volume size, as it cannot expand the volume of a cache. Only an increase,
+	// which validateVolumeResize verifies, is exempted from its immutability check: the extension keeps the volume
+	// it created, the controller reports the difference with the VolumeResizeRequested condition, and the Kyma Control
+	// Plane expands the volume of the cache in the shoot. A decrease is still reported by the extension.
+	if newDefaulted.Spec.Volume.Size.Cmp(*oldDefaulted.Spec.Volume.Size) > 0 {
+		oldDefaulted.Spec.Volume.Size = newDefaulted.Spec.Volume.Size
+	}
 
-	gardenerValidations := registrycacheextvalidations.ValidateRegistryConfigUpdate(toExtensionConfig(*oldConfig), toExtensionConfig(*newConfig), field.NewPath("spec"))
+	gardenerValidations := registrycacheextvalidations.ValidateRegistryConfigUpdate(toExtensionConfig(*oldDefaulted), toExtensionConfig(*newDefaulted), field.NewPath("spec"))
 
-	return append(allErrs, transformFieldErrors(gardenerValidations)...)
+	return warnings, append(allErrs, transformFieldErrors(gardenerValidations)...)
 }
 
-func (v Validator) validateCommon(newConfig *registrycache.RegistryCacheConfig) field.ErrorList {
-	allErrs := field.ErrorList{}
+// validateCommon runs the checks which depend on the cluster, DNS, or the upstream registry concurrently
+// under a common deadline. Failed DNS checks are returned as warnings in DNSModeWarn. The oldConfig is nil on creation
+// and on revalidation, onCreation is only set on creation.
+func (v Validator) validateCommon(ctx context.Context, newConfig, oldConfig *registrycache.RegistryCacheConfig, onCreation bool) (admission.Warnings, field.ErrorList) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, v.timeout)
+		defer cancel()
+	}
 
-	allErrs = append(allErrs, validateUpstreamUniqueness(newConfig, v.runtimeClient)...)
-	allErrs = append(allErrs, validateUpstreamResolvability(newConfig, v.dnsValidator)...)
-	allErrs = append(allErrs, validateRemoteURLResolvability(newConfig, v.dnsValidator)...)
-	allErrs = append(allErrs, validateSecretReference(newConfig, v.runtimeClient)...)
+	dnsResult := make(chan field.ErrorList, 1)
+	go func() {
+		dnsResult <- runChecks(ctx, v.timeout, v.dnsChecks(newConfig)...)
+	}()
+
+	storageClassWarnings := make(chan admission.Warnings, 1)
+	checks := []check{
+		{
+			fldPath:     field.NewPath("spec").Child("upstream"),
+			description: "uniqueness check of the upstream",
+			run: func(ctx context.Context) field.ErrorList {
+				return validateUpstreamUniqueness(ctx, newConfig, v.runtimeClient)
+			},
+		},
+		{
+			fldPath:     field.NewPath("spec").Child("secretReferenceName"),
+			description: "verification of the referenced secret",
+			run: func(ctx context.Context) field.ErrorList {
+				secretErrs := validateSecretReference(ctx, newConfig, v.runtimeClient)
+				if len(secretErrs) > 0 {
+					return secretErrs
+				}
+				return validateCredentials(ctx, newConfig, v.runtimeClient, v.credentialsVerifier)
+			},
+		},
+	}
 
-	return allErrs
+	// the policy applies to new configs and changes only, so that existing configs stay valid when it is tightened
+	if onCreation || oldConfig != nil {
+		checks = append(checks, check{
+			fldPath:     field.NewPath("spec"),
+			description: "enforcement of the RegistryCache policy",
+			run: func(ctx context.Context) field.ErrorList {
+				return validatePolicy(ctx, newConfig, oldConfig, v.runtimeClient)
+			},
+		})
+	}
+
+	if oldConfig != nil {
+		checks = append(checks, check{
+			fldPath:     field.NewPath("spec").Child("volume", "size"),
+			description: "verification of the volume resize",
+			run: func(ctx context.Context) field.ErrorList {
+				return validateVolumeResize(ctx, newConfig, oldConfig, v.runtimeClient)
+			},
+		})
+	}
+
+	// the storage class is immutable, so it is checked only on creation
+	if onCreation {
+		checks = append(checks, check{
+			fldPath:     field.NewPath("spec").Child("volume", "storageClassName"),
+			description: "verification of the storage class",
+			run: func(ctx context.Context) field.ErrorList {
+				warnings, errs := validateStorageClass(ctx, newConfig, v.runtimeClient)
+				storageClassWarnings <- warnings
+				return errs
+			},
+		})
+	}
+
+	allErrs := runChecks(ctx, v.timeout, checks...)
+	allErrs = append(allErrs, validateProxy(newConfig)...)
+	allErrs = append(allErrs, validatePrewarm(newConfig)...)
+
+	var warnings admission.Warnings
+	select {
+	case warnings = <-storageClassWarnings:
+	default:
+	}
+
+	dnsErrs := <-dnsResult
+	if v.dnsMode == DNSModeWarn {
+		return append(warnings, dnsWarnings(dnsErrs)...), allErrs
+	}
+
+	return warnings, append(allErrs, dnsErrs...)
+}
+
+// dnsChecks returns the DNS lookups of the upstream and the remoteURL, none in DNSModeOff.
+func (v Validator) dnsChecks(newConfig *registrycache.RegistryCacheConfig) []check {
+	if v.dnsMode == DNSModeOff {
+		return nil
+	}
+
+	return []check{
+		{
+			fldPath:     field.NewPath("spec").Child("upstream"),
+			description: "DNS lookup of the upstream",
+			run: func(ctx context.Context) field.ErrorList {
+				return validateUpstreamResolvability(ctx, newConfig, v.dnsValidator)
+			},
+		},
+		{
+			fldPath:     field.NewPath("spec").Child("remoteURL"),
+			description: "DNS lookup of the remoteURL",
+			run: func(ctx context.Context) field.ErrorList {
+				return validateRemoteURLResolvability(ctx, newConfig, v.dnsValidator)
+			},
+		},
+	}
 }
 
 func toExtensionConfig(rc registrycache.RegistryCacheConfig) *registrycacheext.RegistryConfig {
@@ -111,10 +286,14 @@ func toExtensionCache(c registrycache.RegistryCacheConfigSpec) registrycacheext.
 	return ext
 }
 
-func validateUpstreamUniqueness(newConfig *registrycache.RegistryCacheConfig, runtimeClient client.Client) field.ErrorList {
+// validateUpstreamUniqueness looks up the configs with the same upstream through the index.RegistryCacheConfigUpstream field index.
+func validateUpstreamUniqueness(ctx context.Context, newConfig *registrycache.RegistryCacheConfig, runtimeClient client.Client) field.ErrorList {
+	if newConfig.Spec.Upstream == "" {
+		return nil
+	}
 
 	var existingConfigs registrycache.RegistryCacheConfigList
-	err := runtimeClient.List(context.Background(), &existingConfigs)
+	err := runtimeClient.List(ctx, &existingConfigs, client.MatchingFields{index.RegistryCacheConfigUpstream: upstream.Normalize(newConfig.Spec.Upstream)})
 	if err != nil {
 		return field.ErrorList{field.InternalError(field.NewPath("spec").Child("upstream"), errors.Wrap(err, "failed to list existing registry cache configs"))}
 	}
@@ -124,20 +303,18 @@ func validateUpstreamUniqueness(newConfig *registrycache.RegistryCacheConfig, ru
 			continue
 		}
 
-		if existingConfig.Spec.Upstream == newConfig.Spec.Upstream {
-			return field.ErrorList{field.Duplicate(field.NewPath("spec").Child("upstream"), newConfig.Spec.Upstream)}
-		}
+		return field.ErrorList{field.Duplicate(field.NewPath("spec").Child("upstream"), newConfig.Spec.Upstream)}
 	}
 
 	return nil
 }
 
-func validateUpstreamResolvability(newConfig *registrycache.RegistryCacheConfig, dns DNSValidator) field.ErrorList {
+func validateUpstreamResolvability(ctx context.Context, newConfig *registrycache.RegistryCacheConfig, dns DNSValidator) field.ErrorList {
 	var allErrs field.ErrorList
 
 	if newConfig.Spec.Upstream != "" {
-		host := stripPort(newConfig.Spec.Upstream)
-		if dns != nil && host != "" && !dns.IsResolvable(host) {
+		host := upstream.Host(newConfig.Spec.Upstream)
+		if dns != nil && host != "" && !dns.IsResolvable(ctx, host) {
 			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("upstream"), newConfig.Spec.Upstream, "upstream is not DNS resolvable"))
 		}
 	}
@@ -145,7 +322,7 @@ func validateUpstreamResolvability(newConfig *registrycache.RegistryCacheConfig,
 	return allErrs
 }
 
-func validateRemoteURLResolvability(newConfig *registrycache.RegistryCacheConfig, dns DNSValidator) field.ErrorList {
+func validateRemoteURLResolvability(ctx context.Context, newConfig *registrycache.RegistryCacheConfig, dns DNSValidator) field.ErrorList {
 	var allErrs field.ErrorList
 
 	if newConfig.Spec.RemoteURL != nil {
@@ -157,7 +334,7 @@ func validateRemoteURLResolvability(newConfig *registrycache.RegistryCacheConfig
 		}
 
 		host := parsed.Hostname()
-		if host != "" && dns != nil && !dns.IsResolvable(host) {
+		if host != "" && dns != nil && !dns.IsResolvable(ctx, host) {
 			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("remoteURL"), newConfig.Spec.RemoteURL, "remoteURL is not DNS resolvable"))
 		}
 	}
@@ -165,11 +342,11 @@ func validateRemoteURLResolvability(newConfig *registrycache.RegistryCacheConfig
 	return allErrs
 }
 
-func validateSecretReference(newConfig *registrycache.RegistryCacheConfig, runtimeClient client.Client) field.ErrorList {
+func validateSecretReference(ctx context.Context, newConfig *registrycache.RegistryCacheConfig, runtimeClient client.Client) field.ErrorList {
 	if newConfig.Spec.SecretReferenceName != nil {
 
 		var registryCacheSecret v1.Secret
-		err := runtimeClient.Get(context.Background(), types.NamespacedName{
+		err := runtimeClient.Get(ctx, types.NamespacedName{
 			Name:      *newConfig.Spec.SecretReferenceName,
 			Namespace: newConfig.Namespace,
 		}, &registryCacheSecret)
@@ -183,6 +360,10 @@ func validateSecretReference(newConfig *registrycache.RegistryCacheConfig, runti
 			return field.ErrorList{field.InternalError(field.NewPath("spec").Child("secretReferenceName"), errors.Wrap(err, "failed to get secret"))}
 		}
 
+		if credentials.NeedsDerivation(&registryCacheSecret) {
+			return validateDerivableSecret(&registryCacheSecret, newConfig)
+		}
+
 		return registrycacheextvalidations.ValidateUpstreamRegistrySecret(&registryCacheSecret, field.NewPath("spec").Child("secretReferenceName"), *newConfig.Spec.SecretReferenceName)
 	}
 
@@ -196,14 +377,26 @@ func isEmptySpec(s registrycache.RegistryCacheConfigSpec) bool {
 		s.GarbageCollection == nil &&
 		s.Proxy == nil &&
 		s.SecretReferenceName == nil &&
-		s.HTTP == nil
+		s.HTTP == nil &&
+		s.Prewarm == nil
 }
 
+// transformFieldErrors maps the errors of the extension validations to the paths of the RegistryCacheConfig.
+// URLs with user information are redacted, so that passwords do not end up in the API response.
+// The user information in proxy URLs is reported by validateProxy.
 func transformFieldErrors(errs field.ErrorList) field.ErrorList {
 	var crdErrors field.ErrorList
 
 	for _, extensionError := range errs {
 		extensionError.Field = adjustPath(extensionError.Field)
+		if strings.HasPrefix(extensionError.Field, "spec.proxy.") && extensionError.Detail == extensionUserInfoDetail {
+			continue
+		}
+		if rawURL, ok := extensionError.BadValue.(string); ok {
+			if parsed, err := url.Parse(rawURL); err == nil && parsed.User != nil {
+				extensionError.BadValue = parsed.Redacted()
+			}
+		}
 		crdErrors = append(crdErrors, extensionError)
 	}
 
@@ -222,15 +415,3 @@ func adjustPath(extensionPath string) string {
 
 	return strings.Join(partsExtracted, ".")
 }
-
-func stripPort(s string) string {
-	if h, _, err := net.SplitHostPort(s); err == nil && h != "" {
-		return h
-	}
-	if strings.Count(s, ":") == 1 {
-		if idx := strings.IndexByte(s, ':'); idx > 0 {
-			return s[:idx]
-		}
-	}
-	return s
-}
@@ -1,6 +1,7 @@
 package validations
 
 import (
+	"context"
 	"k8s.io/apimachinery/pkg/runtime"
 	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -11,10 +12,12 @@ import (
 
 	registrycacheext "github.com/gardener/gardener-extension-registry-cache/pkg/apis/registry"
 	registrycache "github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/index"
 	"github.com/kyma-project/registry-cache/internal/webhook/validations/mocks"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -74,10 +77,10 @@ func newTestEnv() testEnv {
 		dnsResolver:      &mocks.DNSValidator{},
 	}
 
-	env.dnsResolverAllOK.On("IsResolvable", mock.Anything).Return(true)
-	env.dnsResolver.On("IsResolvable", "docker.io").Return(true)
-	env.dnsResolver.On("IsResolvable", "registry-not-existing.not-exists.io").Return(false)
-	env.dnsResolver.On("IsResolvable", "some.incorrect.repo.io").Return(false)
+	env.dnsResolverAllOK.On("IsResolvable", mock.Anything, mock.Anything).Return(true)
+	env.dnsResolver.On("IsResolvable", mock.Anything, "docker.io").Return(true)
+	env.dnsResolver.On("IsResolvable", mock.Anything, "registry-not-existing.not-exists.io").Return(false)
+	env.dnsResolver.On("IsResolvable", mock.Anything, "some.incorrect.repo.io").Return(false)
 
 	return env
 }
@@ -91,14 +94,14 @@ func TestDo(t *testing.T) {
 			RemoteURL: ptr.To("https://registry-1.docker.io"),
 		})
 
-		errs := NewValidator(env.dnsResolverAllOK, fixFakeClient()).Do(&cfg)
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient()).Do(context.Background(), &cfg)
 		validateResult(t, field.ErrorList{}, errs)
 	})
 
 	t.Run("spec emptiness", func(t *testing.T) {
 		cfg := buildConfig("config1", "default", registrycache.RegistryCacheConfigSpec{})
 
-		errs := NewValidator(env.dnsResolverAllOK, fixFakeClient()).Do(&cfg)
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient()).Do(context.Background(), &cfg)
 		validateResult(t, field.ErrorList{
 			field.Required(field.NewPath("spec"), "spec must not be empty"),
 		}, errs)
@@ -120,7 +123,7 @@ func TestDo(t *testing.T) {
 			},
 		})
 
-		errs := NewValidator(env.dnsResolverAllOK, fixFakeClient()).Do(&cfg)
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient()).Do(context.Background(), &cfg)
 		validateResult(t, field.ErrorList{
 			field.Invalid(env.upstreamFieldPath, InvalidUpstreamPort, "valid port must be in the range [1, 65535]"),
 			field.Invalid(env.remoteURLFieldPath, InvalidRemoteURL, "url must start with 'http://' or 'https://'"),
@@ -137,17 +140,27 @@ func TestDo(t *testing.T) {
 		})
 		existing := buildConfig("config1", "test", registrycache.RegistryCacheConfigSpec{Upstream: "docker.io"})
 
-		errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&existing)).Do(&cfg)
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&existing)).Do(context.Background(), &cfg)
 		validateResult(t, field.ErrorList{
 			field.Duplicate(env.upstreamFieldPath, "docker.io"),
 		}, errs)
 	})
 
+	t.Run("uniqueness with other upstreams", func(t *testing.T) {
+		cfg := buildConfig("config2", "default", registrycache.RegistryCacheConfigSpec{
+			Upstream: "docker.io",
+		})
+		unrelated := buildConfig("config1", "test", registrycache.RegistryCacheConfigSpec{Upstream: "quay.io"})
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&unrelated)).Do(context.Background(), &cfg)
+		require.Empty(t, errs)
+	})
+
 	t.Run("upstream not resolvable", func(t *testing.T) {
 		cfg := buildConfig("config1", "default", registrycache.RegistryCacheConfigSpec{
 			Upstream: "some.incorrect.repo.io",
 		})
-		errs := NewValidator(env.dnsResolver, fixFakeClient()).Do(&cfg)
+		_, errs := NewValidator(env.dnsResolver, fixFakeClient()).Do(context.Background(), &cfg)
 		validateResult(t, field.ErrorList{
 			field.Invalid(env.upstreamFieldPath, "some.incorrect.repo.io", "upstream is not DNS resolvable"),
 		}, errs)
@@ -158,19 +171,33 @@ func TestDo(t *testing.T) {
 			Upstream:  "docker.io",
 			RemoteURL: ptr.To("https://registry-not-existing.not-exists.io"),
 		})
-		errs := NewValidator(env.dnsResolver, fixFakeClient()).Do(&cfg)
+		_, errs := NewValidator(env.dnsResolver, fixFakeClient()).Do(context.Background(), &cfg)
 		validateResult(t, field.ErrorList{
 			field.Invalid(env.remoteURLFieldPath, ptr.To("https://registry-not-existing.not-exists.io"), "remoteURL is not DNS resolvable"),
 		}, errs)
 	})
 
+	t.Run("remoteURL of another registry", func(t *testing.T) {
+		for _, remoteURL := range []string{"https://mirror.gcr.io", "https://registry-1.docker.io"} {
+			t.Run(remoteURL, func(t *testing.T) {
+				cfg := buildConfig("config1", "default", registrycache.RegistryCacheConfigSpec{
+					Upstream:  "docker.io",
+					RemoteURL: ptr.To(remoteURL),
+				})
+				_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient()).Do(context.Background(), &cfg)
+
+				validateResult(t, nil, errs)
+			})
+		}
+	})
+
 	t.Run("secret validity", func(t *testing.T) {
 		t.Run("non existent", func(t *testing.T) {
 			cfg := buildConfig("config1", "default", registrycache.RegistryCacheConfigSpec{
 				Upstream:            "docker.io",
 				SecretReferenceName: ptr.To("non-existent-secret"),
 			})
-			errs := NewValidator(env.dnsResolverAllOK, fixFakeClient()).Do(&cfg)
+			_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient()).Do(context.Background(), &cfg)
 			validateResult(t, field.ErrorList{
 				field.Invalid(fieldPathSpec("secretReferenceName"), "non-existent-secret", "secret does not exist"),
 			}, errs)
@@ -180,7 +207,7 @@ func TestDo(t *testing.T) {
 				Upstream:            "docker.io",
 				SecretReferenceName: ptr.To(env.invalidSecret.Name),
 			})
-			errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&env.invalidSecret)).Do(&cfg)
+			_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&env.invalidSecret)).Do(context.Background(), &cfg)
 			validateResult(t, field.ErrorList{
 				field.Invalid(fieldPathSpec("secretReferenceName"), env.invalidSecret.Name, "two data entries"),
 				field.Invalid(fieldPathSpec("secretReferenceName"), env.invalidSecret.Name, "missing \"username\" data entry"),
@@ -192,7 +219,7 @@ func TestDo(t *testing.T) {
 				Upstream:            "docker.io",
 				SecretReferenceName: ptr.To(env.mutableSecret.Name),
 			})
-			errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&env.mutableSecret)).Do(&cfg)
+			_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&env.mutableSecret)).Do(context.Background(), &cfg)
 			validateResult(t, field.ErrorList{
 				field.Invalid(fieldPathSpec("secretReferenceName"), env.mutableSecret.Name, "should be immutable"),
 			}, errs)
@@ -212,7 +239,28 @@ func TestDoOnUpdate(t *testing.T) {
 			Upstream:            "docker.io",
 			SecretReferenceName: ptr.To(env.validSecret.Name),
 		})
-		errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&env.validSecret, &oldCfg)).DoOnUpdate(&newCfg, &oldCfg)
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&env.validSecret, &oldCfg)).DoOnUpdate(context.Background(), &newCfg, &oldCfg)
+		validateResult(t, field.ErrorList{}, errs)
+	})
+
+	t.Run("defaulted values of an object stored without defaults", func(t *testing.T) {
+		oldCfg := buildConfig("config1", "default", registrycache.RegistryCacheConfigSpec{
+			Upstream: "docker.io",
+		})
+		newCfg := buildConfig("config1", "default", registrycache.RegistryCacheConfigSpec{
+			Upstream:  "docker.io",
+			RemoteURL: ptr.To("https://registry-1.docker.io"),
+			Volume: &registrycache.Volume{
+				Size: ptr.To(resource.MustParse("10Gi")),
+			},
+			GarbageCollection: &registrycache.GarbageCollection{
+				TTL: metav1.Duration{Duration: 168 * time.Hour},
+			},
+			HTTP: &registrycache.HTTP{
+				TLS: true,
+			},
+		})
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&oldCfg)).DoOnUpdate(context.Background(), &newCfg, &oldCfg)
 		validateResult(t, field.ErrorList{}, errs)
 	})
 
@@ -221,7 +269,7 @@ func TestDoOnUpdate(t *testing.T) {
 			Upstream: "quay.io",
 		})
 		newCfg := buildConfig("config1", "default", registrycache.RegistryCacheConfigSpec{})
-		errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&oldCfg)).DoOnUpdate(&newCfg, &oldCfg)
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&oldCfg)).DoOnUpdate(context.Background(), &newCfg, &oldCfg)
 		validateResult(t, field.ErrorList{
 			field.Required(field.NewPath("spec"), "spec must not be empty"),
 		}, errs)
@@ -246,9 +294,9 @@ func TestDoOnUpdate(t *testing.T) {
 				TTL: metav1.Duration{Duration: 1 * time.Hour},
 			},
 		})
-		errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&oldCfg)).DoOnUpdate(&newCfg, &oldCfg)
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&oldCfg)).DoOnUpdate(context.Background(), &newCfg, &oldCfg)
 		validateResult(t, field.ErrorList{
-			field.Invalid(env.volumeSizeFieldPath, NewVolumeSize, "field is immutable"),
+			field.Forbidden(env.volumeSizeFieldPath, "volume size cannot be increased, the storage class of the volume does not exist"),
 			field.Invalid(env.volumeStorageClassNameFieldPath, ptr.To(NewStorageClassName), "field is immutable"),
 			field.Invalid(env.garbageCollectionTTLFieldPath, &registrycacheext.GarbageCollection{
 				TTL: metav1.Duration{Duration: 1 * time.Hour},
@@ -268,7 +316,7 @@ func TestDoOnUpdate(t *testing.T) {
 			Upstream: "docker.io",
 		})
 
-		errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&oldCfg, &config2)).DoOnUpdate(&newCfg, &oldCfg)
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&oldCfg, &config2)).DoOnUpdate(context.Background(), &newCfg, &oldCfg)
 		validateResult(t, field.ErrorList{
 			field.Duplicate(fieldPathSpec("upstream"), "docker.io"),
 		}, errs)
@@ -282,7 +330,7 @@ func TestDoOnUpdate(t *testing.T) {
 			Upstream: "some.incorrect.repo.io",
 		})
 
-		errs := NewValidator(env.dnsResolver, fixFakeClient(&oldCfg)).DoOnUpdate(&newCfg, &oldCfg)
+		_, errs := NewValidator(env.dnsResolver, fixFakeClient(&oldCfg)).DoOnUpdate(context.Background(), &newCfg, &oldCfg)
 		validateResult(t, field.ErrorList{
 			field.Invalid(fieldPathSpec("upstream"), "some.incorrect.repo.io", "upstream is not DNS resolvable"),
 		}, errs)
@@ -296,7 +344,7 @@ func TestDoOnUpdate(t *testing.T) {
 			Upstream:  "docker.io",
 			RemoteURL: ptr.To("https://registry-not-existing.not-exists.io"),
 		})
-		errs := NewValidator(env.dnsResolver, fixFakeClient(&oldCfg)).DoOnUpdate(&newCfg, &oldCfg)
+		_, errs := NewValidator(env.dnsResolver, fixFakeClient(&oldCfg)).DoOnUpdate(context.Background(), &newCfg, &oldCfg)
 		validateResult(t, field.ErrorList{
 			field.Invalid(fieldPathSpec("remoteURL"), ptr.To("https://registry-not-existing.not-exists.io"), "remoteURL is not DNS resolvable"),
 		}, errs)
@@ -312,7 +360,7 @@ func TestDoOnUpdate(t *testing.T) {
 				Upstream:            "docker.io",
 				SecretReferenceName: ptr.To(env.invalidSecret.Name),
 			})
-			errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&env.invalidSecret, &oldCfg)).DoOnUpdate(&newCfg, &oldCfg)
+			_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&env.invalidSecret, &oldCfg)).DoOnUpdate(context.Background(), &newCfg, &oldCfg)
 			validateResult(t, field.ErrorList{
 				field.Invalid(fieldPathSpec("secretReferenceName"), env.invalidSecret.Name, "two data entries"),
 				field.Invalid(fieldPathSpec("secretReferenceName"), env.invalidSecret.Name, "missing \"username\" data entry"),
@@ -328,7 +376,7 @@ func TestDoOnUpdate(t *testing.T) {
 				Upstream:            "docker.io",
 				SecretReferenceName: ptr.To(env.mutableSecret.Name),
 			})
-			errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&env.validSecret, &env.mutableSecret)).DoOnUpdate(&newCfg, &oldCfg)
+			_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&env.validSecret, &env.mutableSecret)).DoOnUpdate(context.Background(), &newCfg, &oldCfg)
 			validateResult(t, field.ErrorList{
 				field.Invalid(fieldPathSpec("secretReferenceName"), env.mutableSecret.Name, "should be immutable"),
 			}, errs)
@@ -383,6 +431,10 @@ func fixFakeClient(initObjs ...client.Object) client.Client {
 	scheme := runtime.NewScheme()
 	_ = registrycache.AddToScheme(scheme)
 	_ = v1.AddToScheme(scheme)
+	_ = storagev1.AddToScheme(scheme)
 
-	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjs...).Build()
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjs...).
+		WithIndex(&registrycache.RegistryCacheConfig{}, index.RegistryCacheConfigSecretReferenceName, index.SecretReferenceName).
+		WithIndex(&registrycache.RegistryCacheConfig{}, index.RegistryCacheConfigUpstream, index.Upstream).
+		Build()
 }
@@ -0,0 +1,74 @@
+package validations
+
+import (
+	"context"
+	"fmt"
+
+	registrycache "github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/webhook/defaults"
+	"github.com/kyma-project/registry-cache/internal/workload"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// validateVolumeResize accepts an increased volume size if the storage class of the cache volume allows volume expansion.
+// The storage class of the existing volume claim is used, or the one the volume is created with if there is no claim yet.
+func validateVolumeResize(ctx context.Context, newConfig, oldConfig *registrycache.RegistryCacheConfig, runtimeClient client.Client) field.ErrorList {
+	fldPath := field.NewPath("spec").Child("volume", "size")
+	newSize, oldSize := defaults.VolumeSize(newConfig.Spec), defaults.VolumeSize(oldConfig.Spec)
+
+	switch newSize.Cmp(oldSize) {
+	case 0:
+		return nil
+	case -1:
+		return field.ErrorList{field.Forbidden(fldPath, fmt.Sprintf("volume size cannot be decreased from %s to %s", oldSize.String(), newSize.String()))}
+	}
+
+	storageClass, err := volumeStorageClass(ctx, oldConfig, runtimeClient)
+	if err != nil {
+		return field.ErrorList{field.InternalError(fldPath, err)}
+	}
+	if storageClass == nil {
+		return field.ErrorList{field.Forbidden(fldPath, "volume size cannot be increased, the storage class of the volume does not exist")}
+	}
+	if storageClass.AllowVolumeExpansion == nil || !*storageClass.AllowVolumeExpansion {
+		return field.ErrorList{field.Forbidden(fldPath,
+			fmt.Sprintf("volume size cannot be increased, storage class %s does not allow volume expansion", storageClass.Name))}
+	}
+
+	return nil
+}
+
+// volumeStorageClass returns the storage class of the cache volume, or nil if it does not exist.
+func volumeStorageClass(ctx context.Context, config *registrycache.RegistryCacheConfig, runtimeClient client.Client) (*storagev1.StorageClass, error) {
+	var claim v1.PersistentVolumeClaim
+	err := runtimeClient.Get(ctx, types.NamespacedName{Namespace: workload.Namespace, Name: workload.VolumeClaimName(config.Spec.Upstream)}, &claim)
+	if client.IgnoreNotFound(err) != nil {
+		return nil, errors.Wrap(err, "failed to get the volume claim of the registry cache")
+	}
+
+	var name *string
+	switch {
+	case err == nil && claim.Spec.StorageClassName != nil:
+		name = claim.Spec.StorageClassName
+	case config.Spec.Volume != nil && config.Spec.Volume.StorageClassName != nil:
+		name = config.Spec.Volume.StorageClassName
+	default:
+		return DefaultStorageClass(ctx, runtimeClient)
+	}
+
+	var storageClass storagev1.StorageClass
+	if err := runtimeClient.Get(ctx, types.NamespacedName{Name: *name}, &storageClass); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to get storage class")
+	}
+
+	return &storageClass, nil
+}
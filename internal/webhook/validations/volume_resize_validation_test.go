@@ -0,0 +1,109 @@
+package validations
+
+import (
+	"context"
+	"testing"
+
+	registrycache "github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/workload"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+)
+
+func TestValidateVolumeResize(t *testing.T) {
+	env := newTestEnv()
+
+	expandable := buildStorageClass("expandable", storagev1.VolumeBindingWaitForFirstConsumer, nil)
+	expandable.AllowVolumeExpansion = ptr.To(true)
+	fixed := buildStorageClass("fixed", storagev1.VolumeBindingWaitForFirstConsumer, nil)
+	defaultExpandable := buildStorageClass("default", storagev1.VolumeBindingWaitForFirstConsumer, map[string]string{"storageclass.kubernetes.io/is-default-class": "true"})
+	defaultExpandable.AllowVolumeExpansion = ptr.To(true)
+
+	configs := func(storageClassName *string, oldSize, newSize string) (*registrycache.RegistryCacheConfig, *registrycache.RegistryCacheConfig) {
+		oldCfg := buildConfig("config1", "default", registrycache.RegistryCacheConfigSpec{
+			Upstream: "quay.io",
+			Volume: &registrycache.Volume{
+				Size:             ptr.To(resource.MustParse(oldSize)),
+				StorageClassName: storageClassName,
+			},
+		})
+		newCfg := oldCfg.DeepCopy()
+		newCfg.Spec.Volume.Size = ptr.To(resource.MustParse(newSize))
+
+		return newCfg, &oldCfg
+	}
+
+	t.Run("volume size is increased", func(t *testing.T) {
+		newCfg, oldCfg := configs(ptr.To("expandable"), "10Gi", "20Gi")
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&expandable, oldCfg)).DoOnUpdate(context.Background(), newCfg, oldCfg)
+
+		validateResult(t, nil, errs)
+	})
+
+	t.Run("volume size is increased with the default storage class", func(t *testing.T) {
+		newCfg, oldCfg := configs(nil, "10Gi", "20Gi")
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&defaultExpandable, oldCfg)).DoOnUpdate(context.Background(), newCfg, oldCfg)
+
+		validateResult(t, nil, errs)
+	})
+
+	t.Run("storage class of the volume claim is used", func(t *testing.T) {
+		newCfg, oldCfg := configs(nil, "10Gi", "20Gi")
+		claim := v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: workload.VolumeClaimName("quay.io"), Namespace: workload.Namespace},
+			Spec:       v1.PersistentVolumeClaimSpec{StorageClassName: ptr.To("fixed")},
+		}
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&defaultExpandable, &fixed, &claim, oldCfg)).DoOnUpdate(context.Background(), newCfg, oldCfg)
+
+		validateResult(t, field.ErrorList{
+			field.Forbidden(env.volumeSizeFieldPath, "volume size cannot be increased, storage class fixed does not allow volume expansion"),
+		}, errs)
+	})
+
+	t.Run("storage class does not allow volume expansion", func(t *testing.T) {
+		newCfg, oldCfg := configs(ptr.To("fixed"), "10Gi", "20Gi")
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&fixed, oldCfg)).DoOnUpdate(context.Background(), newCfg, oldCfg)
+
+		validateResult(t, field.ErrorList{
+			field.Forbidden(env.volumeSizeFieldPath, "volume size cannot be increased, storage class fixed does not allow volume expansion"),
+		}, errs)
+	})
+
+	t.Run("increased volume size must fit into the storage budget", func(t *testing.T) {
+		newCfg, oldCfg := configs(ptr.To("expandable"), "10Gi", "45Gi")
+		registryCache := &registrycache.RegistryCache{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "kyma-system"},
+			Spec: registrycache.RegistryCacheSpec{
+				Policy: &registrycache.RegistryCachePolicy{
+					Storage: &registrycache.StoragePolicy{Budget: ptr.To(resource.MustParse("50Gi"))},
+				},
+			},
+		}
+		other := buildConfig("other", "default", registrycache.RegistryCacheConfigSpec{Upstream: "docker.io"})
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&expandable, registryCache, oldCfg, &other)).DoOnUpdate(context.Background(), newCfg, oldCfg)
+
+		validateResult(t, field.ErrorList{
+			field.Forbidden(env.volumeSizeFieldPath, "volume size 45Gi exceeds the storage budget of the RegistryCache, 40Gi of 50Gi is left"),
+		}, errs)
+	})
+
+	t.Run("volume size is decreased", func(t *testing.T) {
+		newCfg, oldCfg := configs(ptr.To("expandable"), "20Gi", "10Gi")
+
+		_, errs := NewValidator(env.dnsResolverAllOK, fixFakeClient(&expandable, oldCfg)).DoOnUpdate(context.Background(), newCfg, oldCfg)
+
+		validateResult(t, field.ErrorList{
+			field.Forbidden(env.volumeSizeFieldPath, "volume size cannot be decreased from 20Gi to 10Gi"),
+			field.Invalid(env.volumeSizeFieldPath, "10Gi", "field is immutable"),
+		}, errs)
+	})
+}
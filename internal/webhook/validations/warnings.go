@@ -0,0 +1,109 @@
+package validations
+
+import (
+	"fmt"
+	"strings"
+
+	registrycache "github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/kyma-project/registry-cache/internal/upstream"
+	"github.com/kyma-project/registry-cache/internal/webhook/defaults"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// WarningCode identifies an admission warning. The codes are stable and can be used to filter warnings in automation.
+type WarningCode string
+
+const (
+	// WarningGarbageCollectionDisabled is returned when garbage collection is disabled; it cannot be re-enabled later.
+	WarningGarbageCollectionDisabled WarningCode = "RCW001"
+	// WarningInsecureRemoteURL is returned when the upstream is accessed over plain HTTP.
+	WarningInsecureRemoteURL WarningCode = "RCW002"
+	// WarningTLSDisabled is returned when TLS is disabled for the HTTP server of the registry cache.
+	WarningTLSDisabled WarningCode = "RCW003"
+	// WarningLargeVolumeSize is returned when the requested volume size exceeds LargeVolumeSizeThreshold.
+	WarningLargeVolumeSize WarningCode = "RCW004"
+	// WarningDockerHubWithoutCredentials is returned when Docker Hub is cached anonymously and rate limits apply.
+	WarningDockerHubWithoutCredentials WarningCode = "RCW005"
+	// WarningNotDNSResolvable is returned in DNSModeWarn when the upstream or the remoteURL is not DNS resolvable.
+	WarningNotDNSResolvable WarningCode = "RCW006"
+	// WarningDefaultStorageClass is returned when no storage class is set and the volume uses the default storage class.
+	WarningDefaultStorageClass WarningCode = "RCW007"
+	// WarningRemoteURLOtherRegistry is returned when the remoteURL points to another registry than the upstream, for example, a mirror.
+	WarningRemoteURLOtherRegistry WarningCode = "RCW008"
+)
+
+// LargeVolumeSizeThreshold is the volume size above which a warning is returned.
+var LargeVolumeSizeThreshold = resource.MustParse("1Ti")
+
+// Warnings returns warnings for settings which are valid but are likely mistakes. The oldConfig is nil on creation.
+// Warnings for irreversible changes are returned only when the change is made, not on every following update.
+func Warnings(newConfig, oldConfig *registrycache.RegistryCacheConfig) admission.Warnings {
+	newSpec := defaultedSpec(newConfig)
+	if newSpec.Upstream == "" {
+		return nil
+	}
+
+	var warnings admission.Warnings
+
+	if !garbageCollectionEnabled(newSpec) && (oldConfig == nil || garbageCollectionEnabled(defaultedSpec(oldConfig))) {
+		warnings = append(warnings, formatWarning(WarningGarbageCollectionDisabled, field.NewPath("spec").Child("garbageCollection", "ttl"),
+			"garbage collection is disabled and cannot be enabled again, the cache volume may run out of space"))
+	}
+
+	if newSpec.RemoteURL != nil && strings.HasPrefix(*newSpec.RemoteURL, "http://") {
+		warnings = append(warnings, formatWarning(WarningInsecureRemoteURL, field.NewPath("spec").Child("remoteURL"),
+			"the upstream is accessed over plain HTTP, credentials and images are transferred unencrypted"))
+	}
+
+	if newSpec.RemoteURL != nil {
+		// invalid URLs are reported by the extension validations
+		if remoteUpstream, err := upstream.FromURL(*newSpec.RemoteURL); err == nil && remoteUpstream != upstream.Normalize(newSpec.Upstream) {
+			warnings = append(warnings, formatWarning(WarningRemoteURLOtherRegistry, field.NewPath("spec").Child("remoteURL"),
+				fmt.Sprintf("the remoteURL points to %s and not to the upstream %s, images are pulled from that registry", remoteUpstream, newSpec.Upstream)))
+		}
+	}
+
+	if newSpec.HTTP != nil && !newSpec.HTTP.TLS {
+		warnings = append(warnings, formatWarning(WarningTLSDisabled, field.NewPath("spec").Child("http", "tls"),
+			"TLS is disabled for the registry cache, images are served unencrypted inside the cluster"))
+	}
+
+	if newSpec.Volume != nil && newSpec.Volume.Size != nil && newSpec.Volume.Size.Cmp(LargeVolumeSizeThreshold) > 0 {
+		warnings = append(warnings, formatWarning(WarningLargeVolumeSize, field.NewPath("spec").Child("volume", "size"),
+			fmt.Sprintf("the volume size %s exceeds %s, check whether the cache needs this much storage", newSpec.Volume.Size.String(), LargeVolumeSizeThreshold.String())))
+	}
+
+	if upstream.Normalize(newSpec.Upstream) == upstream.DockerHub && newSpec.SecretReferenceName == nil {
+		warnings = append(warnings, formatWarning(WarningDockerHubWithoutCredentials, field.NewPath("spec").Child("secretReferenceName"),
+			"docker.io is cached without credentials, anonymous pulls are subject to Docker Hub rate limits"))
+	}
+
+	return warnings
+}
+
+func defaultedSpec(config *registrycache.RegistryCacheConfig) registrycache.RegistryCacheConfigSpec {
+	spec := *config.Spec.DeepCopy()
+	defaults.SetDefaults(&spec)
+
+	return spec
+}
+
+func garbageCollectionEnabled(spec registrycache.RegistryCacheConfigSpec) bool {
+	return spec.GarbageCollection == nil || spec.GarbageCollection.TTL.Duration > 0
+}
+
+// dnsWarnings converts the errors of the DNS checks to warnings.
+func dnsWarnings(errs field.ErrorList) admission.Warnings {
+	var warnings admission.Warnings
+	for _, err := range errs {
+		warnings = append(warnings, fmt.Sprintf("%s: %s: %s", WarningNotDNSResolvable, err.Field, err.Detail))
+	}
+
+	return warnings
+}
+
+func formatWarning(code WarningCode, fldPath *field.Path, message string) string {
+	return fmt.Sprintf("%s: %s: %s", code, fldPath.String(), message)
+}
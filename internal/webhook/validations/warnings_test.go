@@ -0,0 +1,124 @@
+package validations
+
+import (
+	"strings"
+	"testing"
+
+	registrycache "github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestWarnings(t *testing.T) {
+	t.Run("no warnings for a safe configuration", func(t *testing.T) {
+		config := buildConfig("config", "default", registrycache.RegistryCacheConfigSpec{
+			Upstream: "quay.io",
+		})
+
+		require.Empty(t, Warnings(&config, nil))
+	})
+
+	t.Run("no warnings for an empty spec", func(t *testing.T) {
+		config := buildConfig("config", "default", registrycache.RegistryCacheConfigSpec{})
+
+		require.Empty(t, Warnings(&config, nil))
+	})
+
+	t.Run("warnings for risky settings", func(t *testing.T) {
+		config := buildConfig("config", "default", registrycache.RegistryCacheConfigSpec{
+			Upstream:  "docker.io",
+			RemoteURL: ptr.To("http://registry-1.docker.io"),
+			Volume: &registrycache.Volume{
+				Size: ptr.To(resource.MustParse("2Ti")),
+			},
+			GarbageCollection: &registrycache.GarbageCollection{
+				TTL: metav1.Duration{Duration: 0},
+			},
+			HTTP: &registrycache.HTTP{
+				TLS: false,
+			},
+		})
+
+		warnings := Warnings(&config, nil)
+
+		require.Equal(t, []WarningCode{
+			WarningGarbageCollectionDisabled,
+			WarningInsecureRemoteURL,
+			WarningTLSDisabled,
+			WarningLargeVolumeSize,
+			WarningDockerHubWithoutCredentials,
+		}, warningCodes(warnings))
+		require.Contains(t, warnings[3], "spec.volume.size: the volume size 2Ti exceeds 1Ti")
+	})
+
+	t.Run("no rate limit warning for docker.io with credentials", func(t *testing.T) {
+		config := buildConfig("config", "default", registrycache.RegistryCacheConfigSpec{
+			Upstream:            "docker.io",
+			SecretReferenceName: ptr.To("docker-credentials"),
+		})
+
+		require.Empty(t, Warnings(&config, nil))
+	})
+
+	t.Run("disabled garbage collection", func(t *testing.T) {
+		enabled := buildConfig("config", "default", registrycache.RegistryCacheConfigSpec{
+			Upstream: "quay.io",
+		})
+		disabled := buildConfig("config", "default", registrycache.RegistryCacheConfigSpec{
+			Upstream: "quay.io",
+			GarbageCollection: &registrycache.GarbageCollection{
+				TTL: metav1.Duration{Duration: 0},
+			},
+		})
+
+		t.Run("is reported when it gets disabled", func(t *testing.T) {
+			require.Equal(t, []WarningCode{WarningGarbageCollectionDisabled}, warningCodes(Warnings(&disabled, &enabled)))
+		})
+
+		t.Run("is not reported again on following updates", func(t *testing.T) {
+			require.Empty(t, Warnings(&disabled, &disabled))
+		})
+	})
+}
+
+func TestRemoteURLWarning(t *testing.T) {
+	for _, tc := range []struct {
+		upstream  string
+		remoteURL string
+		warned    bool
+	}{
+		{upstream: "docker.io", remoteURL: "https://registry-1.docker.io"},
+		{upstream: "docker.io", remoteURL: "https://index.docker.io:443"},
+		{upstream: "quay.io", remoteURL: "https://quay.io/"},
+		{upstream: "quay.io:443", remoteURL: "https://quay.io"},
+		{upstream: "my-registry.io:5000", remoteURL: "https://my-registry.io:5000"},
+		{upstream: "docker.io", remoteURL: "https://mirror.gcr.io", warned: true},
+		{upstream: "my-registry.io:5000", remoteURL: "https://my-registry.io", warned: true},
+	} {
+		t.Run(tc.upstream+" "+tc.remoteURL, func(t *testing.T) {
+			config := buildConfig("config", "default", registrycache.RegistryCacheConfigSpec{
+				Upstream:            tc.upstream,
+				RemoteURL:           ptr.To(tc.remoteURL),
+				SecretReferenceName: ptr.To("credentials"),
+			})
+
+			var expected []WarningCode
+			if tc.warned {
+				expected = []WarningCode{WarningRemoteURLOtherRegistry}
+			}
+			require.Equal(t, expected, warningCodes(Warnings(&config, nil)))
+		})
+	}
+}
+
+func warningCodes(warnings []string) []WarningCode {
+	var codes []WarningCode
+	for _, warning := range warnings {
+		code, _, _ := strings.Cut(warning, ":")
+		codes = append(codes, WarningCode(code))
+	}
+
+	return codes
+}
@@ -0,0 +1,182 @@
+package workload
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kyma-project/registry-cache/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// VolumeUsageReader reads how much space is used on a volume claim mounted by a Pod.
+type VolumeUsageReader interface {
+	// VolumeUsage returns the used bytes of the claim, and false if the usage is not reported for the Pod.
+	VolumeUsage(ctx context.Context, pod *corev1.Pod, claimName string) (int64, bool, error)
+}
+
+// Inspect collects the readiness, restarts, and volume usage of the registry cache workload of the upstream.
+// It returns a nil status if the StatefulSet does not exist yet, and the reasons why the workload is not ready otherwise.
+// The usage is optional, a nil reader or a failed read leaves it out of the status.
+func Inspect(ctx context.Context, c client.Reader, usage VolumeUsageReader, upstream string) (*v1beta1.CacheWorkloadStatus, []string, error) {
+	var statefulSet appsv1.StatefulSet
+	if err := c.Get(ctx, types.NamespacedName{Namespace: Namespace, Name: StatefulSetName(upstream)}, &statefulSet); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("error while getting the statefulset of the registry cache: %w", err)
+	}
+
+	status := &v1beta1.CacheWorkloadStatus{
+		StatefulSetName: statefulSet.Name,
+		Replicas:        ptr.Deref(statefulSet.Spec.Replicas, 1),
+		ReadyReplicas:   statefulSet.Status.ReadyReplicas,
+		ServiceName:     ServiceName(upstream),
+		VolumeClaimName: VolumeClaimName(upstream),
+	}
+
+	var problems []string
+	if status.ReadyReplicas < status.Replicas {
+		problems = append(problems, fmt.Sprintf("statefulset %s/%s has %d/%d ready replicas", Namespace, status.StatefulSetName, status.ReadyReplicas, status.Replicas))
+	}
+
+	pods, err := listPods(ctx, c, &statefulSet)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, pod := range pods {
+		for _, container := range pod.Status.ContainerStatuses {
+			status.Restarts += container.RestartCount
+		}
+	}
+
+	serviceProblem, err := inspectService(ctx, c, status)
+	if err != nil {
+		return nil, nil, err
+	}
+	if serviceProblem != "" {
+		problems = append(problems, serviceProblem)
+	}
+
+	claimProblem, err := inspectVolumeClaim(ctx, c, usage, pods, status)
+	if err != nil {
+		return nil, nil, err
+	}
+	if claimProblem != "" {
+		problems = append(problems, claimProblem)
+	}
+
+	return status, problems, nil
+}
+
+func listPods(ctx context.Context, c client.Reader, statefulSet *appsv1.StatefulSet) ([]corev1.Pod, error) {
+	if statefulSet.Spec.Selector == nil {
+		return nil, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(statefulSet.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector of statefulset %s/%s: %w", Namespace, statefulSet.Name, err)
+	}
+
+	var pods corev1.PodList
+	if err := c.List(ctx, &pods, client.InNamespace(Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("error while listing the pods of the registry cache: %w", err)
+	}
+
+	return pods.Items, nil
+}
+
+// inspectService counts the ready endpoints of the Service. Endpoints without the ready condition are counted as ready,
+// as the EndpointSlice API defines.
+func inspectService(ctx context.Context, c client.Reader, status *v1beta1.CacheWorkloadStatus) (string, error) {
+	var service corev1.Service
+	if err := c.Get(ctx, types.NamespacedName{Namespace: Namespace, Name: status.ServiceName}, &service); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Sprintf("service %s/%s does not exist", Namespace, status.ServiceName), nil
+		}
+		return "", fmt.Errorf("error while getting the service of the registry cache: %w", err)
+	}
+
+	var slices discoveryv1.EndpointSliceList
+	if err := c.List(ctx, &slices, client.InNamespace(Namespace), client.MatchingLabels{discoveryv1.LabelServiceName: status.ServiceName}); err != nil {
+		return "", fmt.Errorf("error while listing the endpoints of the registry cache: %w", err)
+	}
+	for _, slice := range slices.Items {
+		for _, endpoint := range slice.Endpoints {
+			if ptr.Deref(endpoint.Conditions.Ready, true) {
+				status.ReadyEndpoints++
+			}
+		}
+	}
+
+	if status.ReadyEndpoints == 0 {
+		return fmt.Sprintf("service %s/%s has no ready endpoints", Namespace, status.ServiceName), nil
+	}
+	return "", nil
+}
+
+func inspectVolumeClaim(ctx context.Context, c client.Reader, usage VolumeUsageReader, pods []corev1.Pod, status *v1beta1.CacheWorkloadStatus) (string, error) {
+	var claim corev1.PersistentVolumeClaim
+	if err := c.Get(ctx, types.NamespacedName{Namespace: Namespace, Name: status.VolumeClaimName}, &claim); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Sprintf("volume claim %s/%s does not exist", Namespace, status.VolumeClaimName), nil
+		}
+		return "", fmt.Errorf("error while getting the volume claim of the registry cache: %w", err)
+	}
+
+	status.VolumeClaimPhase = claim.Status.Phase
+	if capacity, ok := claim.Status.Capacity[corev1.ResourceStorage]; ok {
+		status.VolumeCapacity = ptr.To(capacity)
+	}
+
+	if usage != nil && status.VolumeCapacity != nil {
+		readVolumeUsage(ctx, usage, pods, status)
+	}
+
+	if claim.Status.Phase != corev1.ClaimBound {
+		return fmt.Sprintf("volume claim %s/%s is %s", Namespace, status.VolumeClaimName, claim.Status.Phase), nil
+	}
+	return "", nil
+}
+
+// readVolumeUsage reads the usage from the Pod which mounts the volume claim.
+func readVolumeUsage(ctx context.Context, usage VolumeUsageReader, pods []corev1.Pod, status *v1beta1.CacheWorkloadStatus) {
+	for _, pod := range pods {
+		if !mountsClaim(&pod, status.VolumeClaimName) {
+			continue
+		}
+
+		used, ok, err := usage.VolumeUsage(ctx, &pod, status.VolumeClaimName)
+		if err != nil {
+			log.FromContext(ctx).V(1).Info("failed to read the volume usage of the registry cache", "pod", pod.Name, "error", err.Error())
+			return
+		}
+		if !ok {
+			return
+		}
+
+		status.VolumeUsed = resource.NewQuantity(used, resource.BinarySI)
+		if capacity := status.VolumeCapacity.Value(); capacity > 0 {
+			status.VolumeUsagePercent = ptr.To(int32(used * 100 / capacity))
+		}
+		return
+	}
+}
+
+func mountsClaim(pod *corev1.Pod, claimName string) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == claimName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package workload
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kyma-project/registry-cache/api/v1beta1"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeVolumeUsage struct {
+	used int64
+	err  error
+}
+
+func (f fakeVolumeUsage) VolumeUsage(_ context.Context, _ *corev1.Pod, _ string) (int64, bool, error) {
+	return f.used, f.err == nil, f.err
+}
+
+func TestInspect(t *testing.T) {
+	const upstream = "docker.io"
+	labels := map[string]string{"app": "registry-docker-io"}
+
+	statefulSet := func(ready int32) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "registry-docker-io", Namespace: Namespace},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: ptr.To[int32](1),
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+			},
+			Status: appsv1.StatefulSetStatus{ReadyReplicas: ready},
+		}
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry-docker-io-0", Namespace: Namespace, Labels: labels},
+		Spec: corev1.PodSpec{
+			NodeName: "node-1",
+			Volumes: []corev1.Volume{{
+				Name:         "cache-volume",
+				VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "cache-volume-registry-docker-io-0"}},
+			}},
+		},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "registry-cache", RestartCount: 3}}},
+	}
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "registry-docker-io", Namespace: Namespace}}
+	endpointSlice := func(ready bool) *discoveryv1.EndpointSlice {
+		return &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "registry-docker-io-abcde",
+				Namespace: Namespace,
+				Labels:    map[string]string{discoveryv1.LabelServiceName: "registry-docker-io"},
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(ready)}}},
+		}
+	}
+	claim := func(phase corev1.PersistentVolumeClaimPhase) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "cache-volume-registry-docker-io-0", Namespace: Namespace},
+			Status: corev1.PersistentVolumeClaimStatus{
+				Phase:    phase,
+				Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+			},
+		}
+	}
+
+	t.Run("statefulset does not exist", func(t *testing.T) {
+		status, problems, err := Inspect(context.Background(), fixFakeClient(), nil, upstream)
+
+		require.NoError(t, err)
+		require.Nil(t, status)
+		require.Empty(t, problems)
+	})
+
+	t.Run("ready workload", func(t *testing.T) {
+		c := fixFakeClient(statefulSet(1), pod, service, endpointSlice(true), claim(corev1.ClaimBound))
+
+		status, problems, err := Inspect(context.Background(), c, fakeVolumeUsage{used: 2 * 1024 * 1024 * 1024}, upstream)
+
+		require.NoError(t, err)
+		require.Empty(t, problems)
+		require.Equal(t, &v1beta1.CacheWorkloadStatus{
+			StatefulSetName:    "registry-docker-io",
+			Replicas:           1,
+			ReadyReplicas:      1,
+			Restarts:           3,
+			ServiceName:        "registry-docker-io",
+			ReadyEndpoints:     1,
+			VolumeClaimName:    "cache-volume-registry-docker-io-0",
+			VolumeClaimPhase:   corev1.ClaimBound,
+			VolumeCapacity:     ptr.To(resource.MustParse("10Gi")),
+			VolumeUsed:         resource.NewQuantity(2*1024*1024*1024, resource.BinarySI),
+			VolumeUsagePercent: ptr.To[int32](20),
+		}, status)
+	})
+
+	t.Run("workload which is not ready", func(t *testing.T) {
+		c := fixFakeClient(statefulSet(0), pod, service, endpointSlice(false), claim(corev1.ClaimPending))
+
+		status, problems, err := Inspect(context.Background(), c, fakeVolumeUsage{err: errors.New("node proxy forbidden")}, upstream)
+
+		require.NoError(t, err)
+		require.Nil(t, status.VolumeUsed)
+		require.Equal(t, []string{
+			"statefulset kube-system/registry-docker-io has 0/1 ready replicas",
+			"service kube-system/registry-docker-io has no ready endpoints",
+			"volume claim kube-system/cache-volume-registry-docker-io-0 is Pending",
+		}, problems)
+	})
+
+	t.Run("service and volume claim do not exist", func(t *testing.T) {
+		c := fixFakeClient(statefulSet(1))
+
+		status, problems, err := Inspect(context.Background(), c, nil, upstream)
+
+		require.NoError(t, err)
+		require.Equal(t, int32(0), status.Restarts)
+		require.Equal(t, []string{
+			"service kube-system/registry-docker-io does not exist",
+			"volume claim kube-system/cache-volume-registry-docker-io-0 does not exist",
+		}, problems)
+	})
+}
+
+func fixFakeClient(objects ...client.Object) client.Client {
+	return fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objects...).Build()
+}
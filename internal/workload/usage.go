@@ -0,0 +1,67 @@
+package workload
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+// KubeletVolumeUsage reads the volume usage from the stats summary of the kubelet, through the node proxy of the API server.
+type KubeletVolumeUsage struct {
+	client rest.Interface
+}
+
+// NewKubeletVolumeUsage constructs a KubeletVolumeUsage from a REST client of the core API group.
+func NewKubeletVolumeUsage(client rest.Interface) *KubeletVolumeUsage {
+	return &KubeletVolumeUsage{client: client}
+}
+
+// statsSummary is the part of the kubelet stats summary which is needed for the volume usage.
+type statsSummary struct {
+	Pods []struct {
+		PodRef struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"podRef"`
+		Volumes []struct {
+			UsedBytes *int64 `json:"usedBytes"`
+			PVCRef    *struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"pvcRef"`
+		} `json:"volume"`
+	} `json:"pods"`
+}
+
+// VolumeUsage implements VolumeUsageReader with the node of the Pod, a Pod which is not scheduled yet has no usage.
+func (k *KubeletVolumeUsage) VolumeUsage(ctx context.Context, pod *corev1.Pod, claimName string) (int64, bool, error) {
+	if pod.Spec.NodeName == "" {
+		return 0, false, nil
+	}
+
+	raw, err := k.client.Get().AbsPath("/api/v1/nodes", pod.Spec.NodeName, "proxy", "stats", "summary").DoRaw(ctx)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get the stats summary of node %s: %w", pod.Spec.NodeName, err)
+	}
+
+	var summary statsSummary
+	if err := json.Unmarshal(raw, &summary); err != nil {
+		return 0, false, fmt.Errorf("failed to parse the stats summary of node %s: %w", pod.Spec.NodeName, err)
+	}
+
+	for _, podStats := range summary.Pods {
+		if podStats.PodRef.Namespace != pod.Namespace || podStats.PodRef.Name != pod.Name {
+			continue
+		}
+		for _, volume := range podStats.Volumes {
+			if volume.PVCRef != nil && volume.PVCRef.Name == claimName && volume.UsedBytes != nil {
+				return *volume.UsedBytes, true, nil
+			}
+		}
+	}
+
+	return 0, false, nil
+}
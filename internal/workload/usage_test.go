@@ -0,0 +1,77 @@
+package workload
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const statsSummaryFixture = `{
+  "node": {"nodeName": "node-1"},
+  "pods": [
+    {
+      "podRef": {"name": "registry-quay-io-0", "namespace": "kube-system"},
+      "volume": [{"name": "cache-volume", "usedBytes": 1024, "pvcRef": {"name": "cache-volume-registry-quay-io-0", "namespace": "kube-system"}}]
+    },
+    {
+      "podRef": {"name": "registry-docker-io-0", "namespace": "kube-system"},
+      "volume": [
+        {"name": "kube-api-access", "usedBytes": 12},
+        {"name": "cache-volume", "usedBytes": 2048, "pvcRef": {"name": "cache-volume-registry-docker-io-0", "namespace": "kube-system"}}
+      ]
+    }
+  ]
+}`
+
+func TestKubeletVolumeUsage(t *testing.T) {
+	var requestedPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPath = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(statsSummaryFixture))
+	}))
+	defer server.Close()
+
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+	usage := NewKubeletVolumeUsage(clientset.CoreV1().RESTClient())
+
+	pod := func(nodeName string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "registry-docker-io-0", Namespace: Namespace},
+			Spec:       corev1.PodSpec{NodeName: nodeName},
+		}
+	}
+
+	t.Run("usage of the volume claim", func(t *testing.T) {
+		used, ok, err := usage.VolumeUsage(context.Background(), pod("node-1"), "cache-volume-registry-docker-io-0")
+
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, int64(2048), used)
+		require.Equal(t, "/api/v1/nodes/node-1/proxy/stats/summary", requestedPath)
+	})
+
+	t.Run("volume claim is not reported", func(t *testing.T) {
+		_, ok, err := usage.VolumeUsage(context.Background(), pod("node-1"), "cache-volume-registry-gcr-io-0")
+
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("pod is not scheduled", func(t *testing.T) {
+		requestedPath = ""
+		_, ok, err := usage.VolumeUsage(context.Background(), pod(""), "cache-volume-registry-docker-io-0")
+
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Empty(t, requestedPath)
+	})
+}
@@ -0,0 +1,34 @@
+package workload
+
+import (
+	registryutils "github.com/gardener/gardener-extension-registry-cache/pkg/utils/registry"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Namespace is the namespace in which the registry cache extension deploys the registry caches.
+const Namespace = metav1.NamespaceSystem
+
+// volumeName is the name of the volume claim template of the registry cache StatefulSet.
+const volumeName = "cache-volume"
+
+// StatefulSetName returns the name of the StatefulSet which runs the registry cache of the upstream.
+func StatefulSetName(upstream string) string {
+	return registryutils.ComputeKubernetesResourceName(upstream)
+}
+
+// Labels returns the labels of the registry cache Pods of the upstream.
+func Labels(upstream string) map[string]string {
+	return registryutils.GetLabels(StatefulSetName(upstream), registryutils.ComputeUpstreamLabelValue(upstream))
+}
+
+// ServiceName returns the name of the Service in front of the registry cache of the upstream.
+// The module does not configure a service name suffix, so the name equals the StatefulSet name.
+func ServiceName(upstream string) string {
+	return registryutils.ComputeServiceName(upstream, nil)
+}
+
+// VolumeClaimName returns the name of the PersistentVolumeClaim of the cache volume of the upstream.
+// The registry cache StatefulSet has a single replica, so the claim of the Pod with ordinal 0 is returned.
+func VolumeClaimName(upstream string) string {
+	return volumeName + "-" + StatefulSetName(upstream) + "-0"
+}
@@ -0,0 +1,15 @@
+package workload
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNames(t *testing.T) {
+	require.Equal(t, "registry-docker-io", StatefulSetName("docker.io"))
+	require.Equal(t, "registry-docker-io", ServiceName("docker.io"))
+	require.Equal(t, map[string]string{"app": "registry-docker-io", "upstream-host": "docker.io"}, Labels("docker.io"))
+	require.Equal(t, "cache-volume-registry-docker-io-0", VolumeClaimName("docker.io"))
+	require.Equal(t, "cache-volume-registry-my-registry-io-5000-0", VolumeClaimName("my-registry.io:5000"))
+}